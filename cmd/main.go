@@ -4,16 +4,17 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
 
-	awsConfig "github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/aws/aws-sdk-go-v2/service/sqs"
-	"github.com/rk1165/loadsimulator/internal/aws"
+	"github.com/rk1165/loadsimulator/internal/assets"
 	"github.com/rk1165/loadsimulator/internal/config"
-	"github.com/rk1165/loadsimulator/internal/kafka"
-	"github.com/rk1165/loadsimulator/internal/load"
 	"github.com/rk1165/loadsimulator/internal/logger"
-	"github.com/rk1165/loadsimulator/internal/rest"
+	"github.com/rk1165/loadsimulator/internal/plan"
+	"github.com/rk1165/loadsimulator/internal/report"
 	"github.com/rk1165/loadsimulator/internal/types"
 )
 
@@ -22,77 +23,130 @@ func main() {
 	configType := flag.String("configType", "", "The type of config to load")
 	subConfig := flag.String("subConfig", "", "The type of subconfig to load")
 	scenarioName := flag.String("scenario", "", "The name of the scenario to load")
+	configFile := flag.String("config", "", "The path of the config file of the scenario, configs/<subConfig>.yaml when empty")
+	planName := flag.String("plan", "", "The name or the .yaml path of the plan running several scenarios at once, instead of -configType, -subConfig and -scenario")
+	dataDir := flag.String("dataDir", "", "The directory the data files of the scenarios are read from, the working directory when empty")
+	reportFile := flag.String("report", "", "The file to write the run report to (.json, .csv, .xml for JUnit or .html)")
+	reportFormat := flag.String("reportFormat", "", "The format of the report (json, csv, junit or html), guessed from -report when empty")
 	flag.Parse()
+	assets.SetDataDir(*dataDir)
 
-	logger.InfoLog.Printf("configType=%s scenarioName=%s\n", *configType, *scenarioName)
+	// the first SIGINT or SIGTERM stops the run gracefully, a second one kills the process
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
 
-	configFile := fmt.Sprintf("configs/%s.yaml", *subConfig)
-	ctx := context.Background()
+	var testPlan types.Plan
+	if *planName != "" {
+		logger.InfoLog.Printf("plan=%s\n", *planName)
+		var err error
+		testPlan, err = config.LoadPlan(planFile(*planName))
+		if err != nil {
+			logger.ErrorLog.Fatal(err)
+		}
+	} else {
+		logger.InfoLog.Printf("configType=%s scenarioName=%s\n", *configType, *scenarioName)
+		testPlan.Scenarios = []types.PlanScenario{{ConfigType: *configType, SubConfig: *subConfig, Scenario: *scenarioName, Config: *configFile}}
+	}
 
-	ch := make(chan *load.Stats, 1)
-	var runner *load.Runner
-	switch *configType {
-	case "rest":
-		apiConfig, cfg, err := config.LoadTestConfig[types.ApiConfig](configFile, *scenarioName)
+	var scenarios []*plan.Scenario
+	for _, s := range testPlan.Scenarios {
+		scenario, err := plan.NewScenario(ctx, s)
 		if err != nil {
 			logger.ErrorLog.Fatal(err)
 		}
-		var httpLoad load.Load
-		if *subConfig == "post" {
-			httpLoad = rest.NewPost(apiConfig, cfg)
-		} else {
-			httpLoad = rest.NewGet(apiConfig, cfg)
+		scenarios = append(scenarios, scenario)
+	}
+
+	var reports []*report.Report
+	if testPlan.Mode == types.PlanModeSuite {
+		setup, err := newSteps(ctx, testPlan.Setup)
+		if err != nil {
+			logger.ErrorLog.Fatal(err)
 		}
-		runner = load.NewLoadRunner(httpLoad, cfg)
-	case "aws":
-		if *subConfig == "s3" {
-			s3Config, cfg, err := config.LoadTestConfig[types.S3Config](configFile, *scenarioName)
-			if err != nil {
-				logger.ErrorLog.Fatal(err)
-			}
-			awsS3Config, err := awsConfig.LoadDefaultConfig(ctx, awsConfig.WithRegion(s3Config.Region))
-			if err != nil {
-				logger.ErrorLog.Fatal(err)
-			}
-			s3Client := s3.NewFromConfig(awsS3Config)
-			s3Load := aws.NewS3(s3Config, cfg, s3Client)
-			runner = load.NewLoadRunner(s3Load, cfg)
-		} else if *subConfig == "sqs" {
-			sqsConfig, cfg, err := config.LoadTestConfig[types.SqsConfig](configFile, *scenarioName)
-			if err != nil {
-				logger.ErrorLog.Fatal(err)
-			}
-			awsSqsConfig, err := awsConfig.LoadDefaultConfig(ctx, awsConfig.WithRegion(sqsConfig.Region))
-			if err != nil {
+		teardown, err := newSteps(ctx, testPlan.Teardown)
+		if err != nil {
+			logger.ErrorLog.Fatal(err)
+		}
+		reports, err = plan.RunSuite(ctx, setup, scenarios, teardown)
+		if err != nil {
+			logger.ErrorLog.Print(err)
+		}
+	} else {
+		rep, err := plan.Run(ctx, scenarios)
+		if err != nil {
+			logger.ErrorLog.Print(err)
+		}
+		if len(rep.Scenarios) > 0 {
+			reports = append(reports, rep)
+		}
+	}
+	if len(reports) == 0 {
+		logger.ErrorLog.Fatal("no scenario ran")
+	}
+
+	var violations []string
+	interrupted := false
+	for _, rep := range reports {
+		if *reportFile != "" {
+			file := phaseFile(*reportFile, rep.Phase)
+			if err := rep.Write(file, *reportFormat); err != nil {
 				logger.ErrorLog.Fatal(err)
 			}
-			sqsClient := sqs.NewFromConfig(awsSqsConfig)
-			sqsLoad := aws.NewSqs(sqsConfig, cfg, sqsClient)
-			if sqsLoad == nil {
-				logger.ErrorLog.Fatal("unable to initialize sqs load")
+			logger.InfoLog.Printf("Report written to %s", file)
+		}
+		for _, s := range rep.Scenarios {
+			for _, result := range s.Thresholds {
+				logger.InfoLog.Printf("[THRESHOLD] phase=%s scenario=%s %s", rep.Phase, s.Name, result)
 			}
-			runner = load.NewLoadRunner(sqsLoad, cfg)
 		}
-	case "kafka":
-		kafkaConfig, cfg, err := config.LoadTestConfig[types.KafkaConfig](configFile, *scenarioName)
-		if err != nil {
-			logger.ErrorLog.Fatal(err)
+		// the warmup phase is excluded from the results the run is judged on
+		if rep.Phase != types.PhaseWarmup {
+			violations = append(violations, rep.Violations()...)
 		}
-		kafkaLoad := kafka.NewKafka(kafkaConfig, cfg)
-		if kafkaLoad == nil {
-			logger.ErrorLog.Fatal("unable to initialize kafka load")
+		interrupted = interrupted || rep.Interrupted()
+	}
+	if len(violations) > 0 {
+		logger.ErrorLog.Printf("%d violation(s):", len(violations))
+		for _, violation := range violations {
+			logger.ErrorLog.Printf("  %s", violation)
 		}
-		logger.InfoLog.Printf("Kafka Loader Initialized")
-		runner = load.NewLoadRunner(kafkaLoad, cfg)
+		os.Exit(1)
+	}
+	if interrupted {
+		os.Exit(130)
 	}
-	if runner != nil {
-		if err := runner.Run(ctx, ch); err != nil {
-			logger.ErrorLog.Fatalf("load run failed for load=%s/%s scenario=%s with error=[%v]",
-				*configType, *subConfig, *scenarioName, err)
+}
+
+func newSteps(ctx context.Context, planSteps []types.PlanStep) ([]*plan.Step, error) {
+	var steps []*plan.Step
+	for _, s := range planSteps {
+		step, err := plan.NewStep(ctx, s)
+		if err != nil {
+			return nil, err
 		}
-		stats := <-ch
-		logger.InfoLog.Printf("Load finished successfully for scenario: %s, stats: %+v", *scenarioName, stats)
-	} else {
-		logger.ErrorLog.Fatal(fmt.Errorf("unknown configType: %s", *configType))
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// planFile is the file of a plan, plans/<name>.yaml unless the plan is given as a .yaml file
+func planFile(name string) string {
+	switch filepath.Ext(name) {
+	case ".yaml", ".yml":
+		return name
+	}
+	return fmt.Sprintf("plans/%s.yaml", name)
+}
+
+// phaseFile is the report file of a phase of a suite, reports/suite_main.json for reports/suite.json
+func phaseFile(file, phase string) string {
+	if phase == "" {
+		return file
 	}
+	ext := filepath.Ext(file)
+	return strings.TrimSuffix(file, ext) + "_" + phase + ext
 }
@@ -0,0 +1,72 @@
+package assets
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FS reads the configs and the plans, from the disk relative to the working directory first
+var FS = Files{}
+
+// Data reads the data files of the scenarios (bodies, payloads, feeders, schemas), from the disk
+// relative to the data dir first
+var Data = Files{}
+
+// SetDataDir sets the directory the relative paths of the data files are resolved against, the
+// working directory when empty
+func SetDataDir(dir string) {
+	Data = Files{dir: dir}
+}
+
+// Files reads a file from the disk, relative to dir unless its path is absolute, and falls back
+// to the embedded file of the same path when there is none. Unlike most fs.FS, absolute paths
+// and paths out of dir are accepted. A directory on the disk hides the embedded one
+type Files struct {
+	dir string
+}
+
+func (f Files) Open(name string) (fs.File, error) {
+	file, err := os.Open(f.path(name))
+	if err == nil {
+		return file, nil
+	}
+	if errors.Is(err, fs.ErrNotExist) && fs.ValidPath(name) {
+		if file, embeddedErr := embedded.Open(name); embeddedErr == nil {
+			return file, nil
+		}
+	}
+	return nil, err
+}
+
+func (f Files) ReadFile(name string) ([]byte, error) {
+	b, err := os.ReadFile(f.path(name))
+	if err == nil {
+		return b, nil
+	}
+	if errors.Is(err, fs.ErrNotExist) && fs.ValidPath(name) {
+		if b, embeddedErr := embedded.ReadFile(name); embeddedErr == nil {
+			return b, nil
+		}
+	}
+	return nil, err
+}
+
+// Source tells where name is read from, its path on the disk or embedded
+func (f Files) Source(name string) string {
+	if _, err := os.Stat(f.path(name)); err != nil && fs.ValidPath(name) {
+		if _, err := fs.Stat(embedded, name); err == nil {
+			return "embedded"
+		}
+	}
+	return f.path(name)
+}
+
+func (f Files) path(name string) string {
+	name = filepath.FromSlash(name)
+	if f.dir == "" || filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(f.dir, name)
+}
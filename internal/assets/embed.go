@@ -2,5 +2,7 @@ package assets
 
 import "embed"
 
-//go:embed "configs" "data"
-var FS embed.FS
+// embedded holds the example configs, data files and plans built into the binary
+//
+//go:embed "configs" "data" "plans"
+var embedded embed.FS
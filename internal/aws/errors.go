@@ -0,0 +1,27 @@
+package aws
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go"
+	"github.com/rk1165/loadsimulator/internal/load"
+)
+
+const (
+	CategoryThrottling      = "aws throttling"
+	CategoryInvalidResponse = "aws invalid response"
+)
+
+// categorized tags an error of an AWS client with its error code, the throttling error
+// codes all fall in CategoryThrottling. Other errors are left to load.Categorize
+func categorized(err error) error {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	if _, ok := retry.DefaultThrottleErrorCodes[apiErr.ErrorCode()]; ok {
+		return load.WithCategory(CategoryThrottling, err)
+	}
+	return load.WithCategory("aws "+apiErr.ErrorCode(), err)
+}
@@ -0,0 +1,32 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	"github.com/rk1165/loadsimulator/internal/load"
+)
+
+func TestCategorized(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&smithy.GenericAPIError{Code: "ThrottlingException", Message: "Rate exceeded"}, CategoryThrottling},
+		{&smithy.GenericAPIError{Code: "SlowDown"}, CategoryThrottling},
+		{fmt.Errorf("operation error S3: PutObject: %w", &smithy.GenericAPIError{Code: "NoSuchBucket"}), "aws NoSuchBucket"},
+		{&smithy.GenericAPIError{Code: "AccessDenied"}, "aws AccessDenied"},
+		{errors.New("connection lost"), load.CategoryOther},
+	}
+	for _, tt := range tests {
+		err := categorized(tt.err)
+		if got := load.Categorize(err); got != tt.want {
+			t.Errorf("Categorize(categorized(%v)) = %q, want %q", tt.err, got, tt.want)
+		}
+		if !errors.Is(err, tt.err) {
+			t.Errorf("categorized(%v) = %v, want it to wrap the error", tt.err, err)
+		}
+	}
+}
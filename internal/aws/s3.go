@@ -4,13 +4,16 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/google/uuid"
 	"github.com/rk1165/loadsimulator/internal/load"
 	"github.com/rk1165/loadsimulator/internal/logger"
+	"github.com/rk1165/loadsimulator/internal/payload"
 	"github.com/rk1165/loadsimulator/internal/types"
 )
 
@@ -20,43 +23,50 @@ type LoadS3 struct {
 	bucket    string
 	key       string
 	extension string
-	body      string
+	payloads  *payload.Pool[string]
 	client    *s3.Client
 	region    string
 }
 
-func NewS3(s3Config types.S3Config, cfg types.Config, client *s3.Client) *LoadS3 {
+func NewS3(s3Config types.S3Config, cfg types.Config, client *s3.Client) (*LoadS3, error) {
+	payloads, err := payload.New(s3Config.BaseConfig, cfg, payload.Text)
+	if err != nil {
+		return nil, err
+	}
 	s3Load := &LoadS3{
 		bucket:    s3Config.Bucket,
 		key:       s3Config.Key,
 		extension: s3Config.Extension,
 		region:    s3Config.Region,
-		body:      s3Config.ResolveBody(),
+		payloads:  payloads,
 		BaseLoad:  load.NewBaseLoad(cfg),
 		log:       logger.CreateLoadLog(cfg.Name),
 		client:    client,
 	}
-	s3Load.log.InfoLog.Printf("Initialized S3Load configs successfully")
-	return s3Load
+	s3Load.log.InfoLog.Printf("Initialized S3Load configs successfully payloads=[%s]", payloads)
+	return s3Load, nil
 }
 
 func (s *LoadS3) Execute(ctx context.Context, id uint64) error {
+	variant, body := s.payloads.Pick()
 	start := time.Now()
-	key := fmt.Sprintf("%s/%s%s", s.key, uuid.New().String(), s.extension)
+	key := fmt.Sprintf("%s%s%s", s.prefix(), uuid.New().String(), s.extension)
 	resp, err := s.client.PutObject(ctx, &s3.PutObjectInput{
 		Bucket: aws.String(s.bucket),
 		Key:    aws.String(key),
-		Body:   bytes.NewReader([]byte(s.body)),
+		Body:   bytes.NewReader([]byte(body)),
 	})
 	if err != nil {
-		return err
+		return categorized(err)
 	}
 	duration := time.Since(start)
 	if s.Success(resp) {
 		s.Record(duration, true)
+		variant.Stats.Record(duration, true)
 		s.log.InfoLog.Printf("[S3 UPLOAD] requestId=%d etag=%s elapsed=%s", id, aws.ToString(resp.ETag), duration)
 	} else {
-		s.Record(duration, false)
+		s.RecordFailure(duration, CategoryInvalidResponse, "no etag in the response")
+		variant.Stats.RecordFailure(duration, CategoryInvalidResponse, "no etag in the response")
 		s.log.ErrorLog.Printf("[S3 UPLOAD] requestId=%d etag=%s elapsed=%s", id, aws.ToString(resp.ETag), duration)
 	}
 	return nil
@@ -68,5 +78,54 @@ func (s *LoadS3) Success(response any) bool {
 }
 
 func (s *LoadS3) CalculateStats() *load.Stats {
-	return s.BaseLoad.CalculateStats()
+	stats := s.BaseLoad.CalculateStats()
+	stats.Variants = s.payloads.Stats()
+	return stats
+}
+
+// Cleanup deletes the objects uploaded by the load, the objects under its key ending with its
+// extension, and returns the number of deleted objects
+func (s *LoadS3) Cleanup(ctx context.Context) (int, error) {
+	deleted := 0
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(s.prefix()),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to list objects of bucket=%s prefix=%s error=[%v]", s.bucket, s.prefix(), err)
+		}
+		var objects []s3types.ObjectIdentifier
+		for _, object := range page.Contents {
+			if strings.HasSuffix(aws.ToString(object.Key), s.extension) {
+				objects = append(objects, s3types.ObjectIdentifier{Key: object.Key})
+			}
+		}
+		if len(objects) == 0 {
+			continue
+		}
+		// a page holds at most 1000 keys which is also the limit of DeleteObjects
+		out, err := s.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(s.bucket),
+			Delete: &s3types.Delete{Objects: objects, Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete objects of bucket=%s error=[%v]", s.bucket, err)
+		}
+		deleted += len(objects) - len(out.Errors)
+		for _, e := range out.Errors {
+			s.log.ErrorLog.Printf("[S3 CLEANUP] key=%s code=%s err=[%s]", aws.ToString(e.Key), aws.ToString(e.Code), aws.ToString(e.Message))
+		}
+		if len(out.Errors) > 0 {
+			return deleted, fmt.Errorf("failed to delete %d objects of bucket=%s", len(out.Errors), s.bucket)
+		}
+	}
+	s.log.InfoLog.Printf("[S3 CLEANUP] deleted %d objects of bucket=%s prefix=%s", deleted, s.bucket, s.prefix())
+	return deleted, nil
+}
+
+// prefix is the common prefix of the keys of the uploaded objects
+func (s *LoadS3) prefix() string {
+	return s.key + "/"
 }
@@ -2,6 +2,7 @@ package aws
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -9,6 +10,7 @@ import (
 	sqsTypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	"github.com/rk1165/loadsimulator/internal/load"
 	"github.com/rk1165/loadsimulator/internal/logger"
+	"github.com/rk1165/loadsimulator/internal/payload"
 	"github.com/rk1165/loadsimulator/internal/types"
 )
 
@@ -16,18 +18,22 @@ type LoadSQS struct {
 	load.BaseLoad
 	log      load.Log
 	queueUrl string
-	body     string
+	payloads *payload.Pool[string]
 	attrs    map[string]sqsTypes.MessageAttributeValue
 	client   *sqs.Client
 	region   string
 }
 
-func NewSqs(sqsConfig types.SqsConfig, cfg types.Config, client *sqs.Client) *LoadSQS {
+func NewSqs(sqsConfig types.SqsConfig, cfg types.Config, client *sqs.Client) (*LoadSQS, error) {
+	payloads, err := payload.New(sqsConfig.BaseConfig, cfg, payload.Text)
+	if err != nil {
+		return nil, err
+	}
 	sqsLoad := &LoadSQS{
 		BaseLoad: load.NewBaseLoad(cfg),
 		client:   client,
 		log:      logger.CreateLoadLog(cfg.Name),
-		body:     sqsConfig.ResolveBody(),
+		payloads: payloads,
 		region:   sqsConfig.Region,
 		attrs:    buildMessageAttributes(sqsConfig.MessageAttributes),
 	}
@@ -36,11 +42,11 @@ func NewSqs(sqsConfig types.SqsConfig, cfg types.Config, client *sqs.Client) *Lo
 		QueueName: aws.String(sqsConfig.Queue),
 	})
 	if err != nil {
-		return nil
+		return nil, fmt.Errorf("unable to get the url of queue=%s error=[%v]", sqsConfig.Queue, err)
 	}
 	sqsLoad.queueUrl = aws.ToString(out.QueueUrl)
-	sqsLoad.log.InfoLog.Printf("Initialized SQSLoad configs successfully")
-	return sqsLoad
+	sqsLoad.log.InfoLog.Printf("Initialized SQSLoad configs successfully payloads=[%s]", payloads)
+	return sqsLoad, nil
 }
 
 func buildMessageAttributes(attrs []types.MessageAttribute) map[string]sqsTypes.MessageAttributeValue {
@@ -74,21 +80,24 @@ func buildMessageAttributes(attrs []types.MessageAttribute) map[string]sqsTypes.
 }
 
 func (s *LoadSQS) Execute(ctx context.Context, id uint64) error {
+	variant, body := s.payloads.Pick()
 	start := time.Now()
 	out, err := s.client.SendMessage(ctx, &sqs.SendMessageInput{
 		QueueUrl:          aws.String(s.queueUrl),
-		MessageBody:       aws.String(s.body),
+		MessageBody:       aws.String(body),
 		MessageAttributes: s.attrs,
 	})
 	if err != nil {
-		return err
+		return categorized(err)
 	}
 	duration := time.Since(start)
 	if s.Success(out) {
 		s.Record(duration, true)
+		variant.Stats.Record(duration, true)
 		s.log.InfoLog.Printf("[SQS SEND] requestId=%d messageId=%s elapsed=%s", id, aws.ToString(out.MessageId), duration)
 	} else {
-		s.Record(duration, false)
+		s.RecordFailure(duration, CategoryInvalidResponse, "no message id in the response")
+		variant.Stats.RecordFailure(duration, CategoryInvalidResponse, "no message id in the response")
 		s.log.ErrorLog.Printf("[SQS SEND] requestId=%d messageId=%s elapsed=%s", id, aws.ToString(out.MessageId), duration)
 	}
 	return nil
@@ -100,5 +109,7 @@ func (s *LoadSQS) Success(response any) bool {
 }
 
 func (s *LoadSQS) CalculateStats() *load.Stats {
-	return s.BaseLoad.CalculateStats()
+	stats := s.BaseLoad.CalculateStats()
+	stats.Variants = s.payloads.Stats()
+	return stats
 }
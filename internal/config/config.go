@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/rk1165/loadsimulator/internal/assets"
@@ -9,41 +10,151 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-func LoadScenarios[T any](fileName string) (map[string]T, error) {
-	logger.InfoLog.Printf("Loading Scenarios from file : %s", fileName)
-	scenarios := make(map[string]T)
+// LoadScenario loads the scenario name of a scenarios file. Only the values of that scenario are
+// interpolated, a reference another scenario cannot resolve does not stop it from loading
+func LoadScenario[T any](fileName, name string) (T, error) {
+	logger.InfoLog.Printf("Loading Scenario %s from file : %s (%s)", name, fileName, assets.FS.Source(fileName))
+	var scenario T
 	b, err := assets.FS.ReadFile(fileName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read scenarios file=%s error=[%v]", fileName, err)
+		return scenario, fmt.Errorf("failed to read scenarios file=%s error=[%v]", fileName, err)
 	}
 
+	var scenarios map[string]yaml.Node
 	if err := yaml.Unmarshal(b, &scenarios); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal scenarios file=%s error=[%v]", fileName, err)
+		return scenario, fmt.Errorf("failed to unmarshal scenarios file=%s error=[%v]", fileName, err)
 	}
-	logger.InfoLog.Printf("Loaded all scenarios successfully from %s", fileName)
-	return scenarios, nil
+	node, ok := scenarios[name]
+	if !ok {
+		return scenario, fmt.Errorf("scenario %s not found in %s", name, fileName)
+	}
+	if err := interpolate(&node); err != nil {
+		return scenario, fmt.Errorf("failed to interpolate scenario=%s file=%s error=[%v]", name, fileName, err)
+	}
+	if err := node.Decode(&scenario); err != nil {
+		return scenario, fmt.Errorf("failed to unmarshal scenario=%s file=%s error=[%v]", name, fileName, err)
+	}
+	logger.InfoLog.Printf("Loaded scenario %s successfully from %s", name, fileName)
+	return scenario, nil
+}
+
+// LoadPlan loads a plan file and checks every scenario and step of the plan names a scenario to run
+func LoadPlan(fileName string) (types.Plan, error) {
+	logger.InfoLog.Printf("Loading Plan from file : %s (%s)", fileName, assets.FS.Source(fileName))
+	var plan types.Plan
+	b, err := assets.FS.ReadFile(fileName)
+	if err != nil {
+		return plan, fmt.Errorf("failed to read plan file=%s error=[%v]", fileName, err)
+	}
+	if err := yaml.Unmarshal(b, &plan); err != nil {
+		return plan, fmt.Errorf("failed to unmarshal plan file=%s error=[%v]", fileName, err)
+	}
+	if err := validatePlan(plan); err != nil {
+		return plan, fmt.Errorf("invalid plan file=%s: %v", fileName, err)
+	}
+	logger.InfoLog.Printf("Loaded plan with %d scenarios from %s", len(plan.Scenarios), fileName)
+	return plan, nil
+}
+
+func validatePlan(plan types.Plan) error {
+	switch plan.Mode {
+	case "", types.PlanModeConcurrent:
+		if len(plan.Setup) > 0 || len(plan.Teardown) > 0 {
+			return fmt.Errorf("setup and teardown steps need mode %s", types.PlanModeSuite)
+		}
+	case types.PlanModeSuite:
+	default:
+		return fmt.Errorf("unknown mode %q", plan.Mode)
+	}
+	if len(plan.Scenarios) == 0 {
+		return errors.New("no scenarios")
+	}
+	phases := map[string]int{types.PhaseWarmup: 0, types.PhaseMain: 1, types.PhaseCooldown: 2}
+	previousPhase := 0
+	names := make(map[string]bool)
+	for i, s := range plan.Scenarios {
+		if err := validatePlanScenario(s, i); err != nil {
+			return err
+		}
+		if names[s.DisplayName()] {
+			return fmt.Errorf("scenario %s appears twice, give them distinct names", s.DisplayName())
+		}
+		names[s.DisplayName()] = true
+		phase, ok := phases[s.PhaseName()]
+		if !ok {
+			return fmt.Errorf("scenario %s: unknown phase %q", s.DisplayName(), s.Phase)
+		}
+		if s.Phase != "" && plan.Mode != types.PlanModeSuite {
+			return fmt.Errorf("scenario %s: phases need mode %s", s.DisplayName(), types.PlanModeSuite)
+		}
+		if phase < previousPhase {
+			return fmt.Errorf("scenario %s: the %s scenarios must come after the %s ones", s.DisplayName(),
+				s.PhaseName(), plan.Scenarios[i-1].PhaseName())
+		}
+		previousPhase = phase
+	}
+	for i, step := range append(plan.Setup, plan.Teardown...) {
+		if err := validatePlanScenario(step.PlanScenario, i); err != nil {
+			return fmt.Errorf("step %v", err)
+		}
+		// the load of a step is built along with the scenarios and logs to the file of its name
+		if names[step.DisplayName()] {
+			return fmt.Errorf("step %s has the name of another scenario or step, give them distinct names", step.DisplayName())
+		}
+		names[step.DisplayName()] = true
+		switch step.Action {
+		case "", types.StepOnce:
+		case types.StepS3Cleanup:
+			if step.ConfigType != "aws" || step.SubConfig != "s3" {
+				return fmt.Errorf("step %s: %s needs an aws s3 scenario", step.DisplayName(), step.Action)
+			}
+		default:
+			return fmt.Errorf("step %s: unknown action %q", step.DisplayName(), step.Action)
+		}
+	}
+	return nil
+}
+
+func validatePlanScenario(s types.PlanScenario, i int) error {
+	if s.ConfigType == "" || s.SubConfig == "" || s.Scenario == "" {
+		return fmt.Errorf("#%d needs a configType, a subConfig and a scenario", i+1)
+	}
+	if s.StartAfter < 0 {
+		return fmt.Errorf("%s: startAfter must be >= 0", s.DisplayName())
+	}
+	return nil
 }
 
 func LoadTestConfig[T types.Provider](configFile string, scenarioName string) (T, types.Config, error) {
 	logger.InfoLog.Printf("Loading TestConfig scenario=%s", scenarioName)
 	var zero T
 
-	scenarios, err := LoadScenarios[T](configFile)
+	scenario, err := LoadScenario[T](configFile, scenarioName)
 	if err != nil {
 		return zero, types.Config{}, err
 	}
-	scenario, ok := scenarios[scenarioName]
-	if !ok {
-		return zero, types.Config{}, fmt.Errorf("scenario %s not found in %s", scenarioName, configFile)
-	}
 	cfg := types.Config{
 		Name:        scenarioName,
 		RatePerSec:  scenario.GetRatePerSecond(),
 		Duration:    scenario.GetDuration(),
 		Concurrency: scenario.GetConcurrentRequests(),
-		InfoLog:     logger.InfoLog,
-		ErrorLog:    logger.ErrorLog,
-		//Jitter:      scenario.GetJitter(),
+		Stages:      scenario.GetStages(),
+		Jitter:      scenario.GetJitter(),
+		Arrival:     scenario.GetArrival(),
+		Seed:        scenario.GetSeed(),
+		Mode:        scenario.GetMode(),
+		Iterations:  scenario.GetIterations(),
+		ThinkTime:   scenario.GetThinkTime(),
+
+		HistogramPrecision: scenario.GetHistogramPrecision(),
+		ReportInterval:     scenario.GetReport().Interval,
+		TimeSeriesFile:     scenario.GetReport().TimeSeriesFile,
+		Thresholds:         scenario.GetThresholds(),
+		Abort:              scenario.GetAbort(),
+		GracePeriod:        scenario.GetGracePeriod(),
+		ErrorPolicy:        scenario.GetErrorPolicy(),
+		InfoLog:            logger.InfoLog,
+		ErrorLog:           logger.ErrorLog,
 	}
 	logger.InfoLog.Printf("Loaded TestConfig scenario=%s", scenarioName)
 	return scenario, cfg, nil
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/rk1165/loadsimulator/internal/logger"
+	"github.com/rk1165/loadsimulator/internal/secret"
+	"gopkg.in/yaml.v3"
+)
+
+// reference matches the ${...} references to interpolate, and the escaped $${
+var reference = regexp.MustCompile(`\$\$\{|\$\{([^}]*)\}`)
+
+// quoted are the styles of the scalars which stay strings whatever their value
+const quoted = yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle | yaml.LiteralStyle | yaml.FoldedStyle | yaml.TaggedStyle
+
+// interpolate resolves the references of the values of a scenario:
+//   - ${NAME} is the environment variable NAME, which must be set
+//   - ${NAME:-default} is default when NAME is not set or empty
+//   - ${file:/path/to/secret} is the content of the file without its trailing newlines
+//   - $${ is a literal ${
+//
+// An unquoted value is typed after interpolation, so that `ratePerSec: ${RPS:-10}` is a number.
+// The values read from files and the values of the sensitive keys, e.g. clientSecret, password or
+// an Authorization header, are registered as secrets to be redacted from the logs and the reports,
+// unless they are a default
+func interpolate(node *yaml.Node) error {
+	return interpolateNode(node, make(map[*yaml.Node]bool))
+}
+
+// interpolateNode interpolates a node once, though aliases may refer to it several times
+func interpolateNode(node *yaml.Node, seen map[*yaml.Node]bool) error {
+	if seen[node] {
+		return nil
+	}
+	seen[node] = true
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, n := range node.Content {
+			if err := interpolateNode(n, seen); err != nil {
+				return err
+			}
+		}
+	case yaml.AliasNode:
+		return interpolateNode(node.Alias, seen)
+	case yaml.MappingNode:
+		// the headers and params are lists of key value pairs, whose value is a secret when the key is
+		sensitivePair := false
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if k, v := node.Content[i], node.Content[i+1]; k.Value == "key" && v.Kind == yaml.ScalarNode {
+				sensitivePair = secret.IsSensitive(v.Value)
+			}
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if value.Kind != yaml.ScalarNode || seen[value] {
+				if err := interpolateNode(value, seen); err != nil {
+					return err
+				}
+				continue
+			}
+			seen[value] = true
+			sensitive := secret.IsSensitive(key.Value) || sensitivePair && key.Value == "value"
+			if err := interpolateScalar(value, key.Value, sensitive); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		return interpolateScalar(node, "", false)
+	}
+	return nil
+}
+
+// interpolateScalar resolves the references of the value of key, a secret when sensitive is set
+func interpolateScalar(node *yaml.Node, key string, sensitive bool) error {
+	value, secrets, defaulted, err := interpolateValue(node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %v", node.Line, err)
+	}
+	if value != node.Value && node.Style&quoted == 0 && !isNull(value) {
+		node.Tag = "" // the tag of the reference, !!str, is resolved again from the value
+	}
+	node.Value = value
+	if sensitive && !defaulted {
+		secrets = append(secrets, value)
+	}
+	for _, s := range secrets {
+		if s != "" && !secret.Add(s) {
+			logger.WarnLog.Printf("[SECRET] line %d: the value of %s is shorter than %d characters, it is not redacted",
+				node.Line, key, secret.MinLength)
+		}
+	}
+	return nil
+}
+
+// interpolateValue returns s with its references resolved, the values read from files and
+// whether a default was used
+func interpolateValue(s string) (string, []string, bool, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil, false, nil
+	}
+	var secrets []string
+	var defaulted bool
+	var err error
+	value := reference.ReplaceAllStringFunc(s, func(match string) string {
+		if err != nil {
+			return match
+		}
+		if match == "$${" {
+			return "${"
+		}
+		ref := match[2 : len(match)-1]
+		if file, ok := strings.CutPrefix(ref, "file:"); ok {
+			var v string
+			v, err = readSecret(file)
+			secrets = append(secrets, v)
+			return v
+		}
+		v, isDefault, e := lookupEnv(ref)
+		err = e
+		defaulted = defaulted || isDefault
+		return v
+	})
+	return value, secrets, defaulted, err
+}
+
+// readSecret returns the content of a secret file without its trailing newlines
+func readSecret(file string) (string, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %v", err)
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// lookupEnv returns the value of the reference ${ref} to an environment variable and whether it
+// is the default of the reference
+func lookupEnv(ref string) (string, bool, error) {
+	name, defaultValue, hasDefault := strings.Cut(ref, ":-")
+	if name == "" {
+		return "", false, fmt.Errorf("invalid reference ${%s}", ref)
+	}
+	if value := os.Getenv(name); value != "" {
+		return value, false, nil
+	}
+	if hasDefault {
+		return defaultValue, true, nil
+	}
+	if _, ok := os.LookupEnv(name); ok {
+		return "", false, nil
+	}
+	return "", false, fmt.Errorf("environment variable %s is not set", name)
+}
+
+// isNull tells if an unquoted value would be read as null
+func isNull(value string) bool {
+	switch value {
+	case "", "~", "null", "Null", "NULL":
+		return true
+	}
+	return false
+}
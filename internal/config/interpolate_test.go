@@ -0,0 +1,150 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rk1165/loadsimulator/internal/secret"
+	"gopkg.in/yaml.v3"
+)
+
+type target struct {
+	Url          string              `yaml:"url"`
+	RatePerSec   int                 `yaml:"ratePerSec"`
+	Enabled      bool                `yaml:"enabled"`
+	Label        string              `yaml:"label"`
+	ClientSecret string              `yaml:"clientSecret"`
+	Headers      []map[string]string `yaml:"headers"`
+}
+
+func decode(t *testing.T, doc string) (target, error) {
+	t.Helper()
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(doc), &node); err != nil {
+		t.Fatal(err)
+	}
+	var v target
+	if err := interpolate(&node); err != nil {
+		return v, err
+	}
+	return v, node.Decode(&v)
+}
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("TEST_HOST", "api.example.com")
+	t.Setenv("TEST_RPS", "25")
+	t.Setenv("TEST_EMPTY", "")
+	got, err := decode(t, `
+url: https://${TEST_HOST}/orders?from=$${start}
+ratePerSec: ${TEST_RPS}
+enabled: ${TEST_ENABLED:-true}
+label: "${TEST_RPS}"
+clientSecret: ${TEST_EMPTY:-fallback}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := target{Url: "https://api.example.com/orders?from=${start}", RatePerSec: 25, Enabled: true,
+		Label: "25", ClientSecret: "fallback"}
+	if got.Url != want.Url || got.RatePerSec != want.RatePerSec || got.Enabled != want.Enabled ||
+		got.Label != want.Label || got.ClientSecret != want.ClientSecret {
+		t.Errorf("interpolate() = %+v, want %+v", got, want)
+	}
+}
+
+func TestInterpolateErrors(t *testing.T) {
+	tests := []string{
+		"url: ${TEST_NOT_SET_ANYWHERE}",
+		"url: ${}",
+		"url: ${:-default}",
+		"url: ${file:" + filepath.Join(os.TempDir(), "no-such-secret-file") + "}",
+	}
+	for _, doc := range tests {
+		if _, err := decode(t, doc); err == nil {
+			t.Errorf("interpolate(%s) = nil error, want one", doc)
+		}
+	}
+}
+
+func TestInterpolateSecrets(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(file, []byte("file-held-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_CLIENT_SECRET", "env-client-secret")
+	t.Setenv("TEST_BEARER", "Bearer env-bearer-token")
+	t.Setenv("TEST_LABEL", "not-a-secret-label")
+	got, err := decode(t, `
+url: https://example.com/${file:`+file+`}
+label: ${TEST_LABEL}
+clientSecret: ${TEST_CLIENT_SECRET}
+headers:
+  - key: Authorization
+    value: ${TEST_BEARER}
+  - key: X-Default
+    value: ${TEST_UNSET_TOKEN:-default-token-value}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Url != "https://example.com/file-held-token" {
+		t.Errorf("url = %q, want the content of the file without its newline", got.Url)
+	}
+	for _, s := range []string{"file-held-token", "env-client-secret", "Bearer env-bearer-token"} {
+		if redacted := secret.Redact("x " + s + " x"); redacted != "x "+secret.Mask+" x" {
+			t.Errorf("Redact(%q) = %q, want it masked", s, redacted)
+		}
+	}
+	for _, s := range []string{"not-a-secret-label", "default-token-value"} {
+		if redacted := secret.Redact(s); redacted != s {
+			t.Errorf("Redact(%q) = %q, want it kept", s, redacted)
+		}
+	}
+}
+
+func TestInterpolateShortSecret(t *testing.T) {
+	t.Setenv("TEST_SHORT_SECRET", "abc")
+	got, err := decode(t, "clientSecret: ${TEST_SHORT_SECRET}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ClientSecret != "abc" {
+		t.Errorf("clientSecret = %q, want abc", got.ClientSecret)
+	}
+	if redacted := secret.Redact("abcdef"); redacted != "abcdef" {
+		t.Errorf("Redact(abcdef) = %q, a secret shorter than %d characters must not be redacted",
+			redacted, secret.MinLength)
+	}
+}
+
+func TestLoadScenario(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "scenarios.yaml")
+	doc := `
+first:
+  url: https://${TEST_FIRST_HOST}
+  ratePerSec: ${TEST_FIRST_RPS:-5}
+second:
+  url: ${TEST_SECOND_NOT_SET}
+`
+	if err := os.WriteFile(file, []byte(doc), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_FIRST_HOST", "first.example.com")
+	// the references of the other scenarios are not resolved
+	got, err := LoadScenario[target](file, "first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Url != "https://first.example.com" || got.RatePerSec != 5 {
+		t.Errorf("LoadScenario() = %+v", got)
+	}
+	if _, err := LoadScenario[target](file, "second"); err == nil ||
+		!strings.Contains(err.Error(), "TEST_SECOND_NOT_SET") {
+		t.Errorf("LoadScenario(second) error = %v, want the unset variable", err)
+	}
+	if _, err := LoadScenario[target](file, "third"); err == nil {
+		t.Error("LoadScenario(third) = nil error, want scenario not found")
+	}
+}
@@ -0,0 +1,90 @@
+package extract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSONPath returns the value at path in a JSON document. Only the child operators are supported,
+// e.g. `$.order.id`, `$.items[0].sku` or `$['order']['id']`. Strings are returned unquoted, other
+// values as JSON
+func JSONPath(body []byte, path string) (string, error) {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return "", err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return "", fmt.Errorf("invalid json document error=[%v]", err)
+	}
+	for _, segment := range segments {
+		switch v := value.(type) {
+		case map[string]any:
+			child, ok := v[segment]
+			if !ok {
+				return "", fmt.Errorf("no %q in %s", segment, path)
+			}
+			value = child
+		case []any:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(v) {
+				return "", fmt.Errorf("no index %s in %s", segment, path)
+			}
+			value = v[i]
+		default:
+			return "", fmt.Errorf("no %q in %s, not an object or an array", segment, path)
+		}
+	}
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	default:
+		b, err := json.Marshal(v)
+		return string(b), err
+	}
+}
+
+// parseJSONPath splits a path into the keys and indexes to follow from the root
+func parseJSONPath(path string) ([]string, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(path), "$")
+	if !ok {
+		return nil, fmt.Errorf("invalid json path %q, expected it to start with $", path)
+	}
+	var segments []string
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("invalid json path %q, empty key", path)
+			}
+			segments = append(segments, key)
+			rest = rest[end+1:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid json path %q, missing ]", path)
+			}
+			segment := strings.Trim(rest[1:end], `'"`)
+			if segment == "" {
+				return nil, fmt.Errorf("invalid json path %q, empty brackets", path)
+			}
+			segments = append(segments, segment)
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("invalid json path %q at %q", path, rest)
+		}
+	}
+	return segments, nil
+}
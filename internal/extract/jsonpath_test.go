@@ -0,0 +1,47 @@
+package extract
+
+import "testing"
+
+const order = `{
+  "order": {"id": "ORD-1", "total": 12.50, "paid": true, "note": null},
+  "items": [{"sku": "BOOK", "quantity": 2}, {"sku": "PEN", "quantity": 10}]
+}`
+
+func TestJSONPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{path: "$.order.id", want: "ORD-1"},
+		{path: "$['order']['id']", want: "ORD-1"},
+		{path: `$["order"].total`, want: "12.50"},
+		{path: "$.order.paid", want: "true"},
+		{path: "$.order.note", want: "null"},
+		{path: "$.items[1].sku", want: "PEN"},
+		{path: "$.items[0]", want: `{"quantity":2,"sku":"BOOK"}`},
+		{path: "$.items[2].sku", wantErr: true},
+		{path: "$.items[-1]", wantErr: true},
+		{path: "$.order.status", wantErr: true},
+		{path: "$.order.id.value", wantErr: true},
+		{path: "order.id", wantErr: true},
+		{path: "$..id", wantErr: true},
+		{path: "$.items[0", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := JSONPath([]byte(order), tt.path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("JSONPath(%s) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("JSONPath(%s) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestJSONPathInvalidDocument(t *testing.T) {
+	if _, err := JSONPath([]byte(`{"order": `), "$.order"); err == nil {
+		t.Error("JSONPath() of an invalid document = nil error, want one")
+	}
+}
@@ -0,0 +1,159 @@
+package extract
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// node is an element of an XML document
+type node struct {
+	name     string
+	attrs    map[string]string
+	text     strings.Builder
+	children []*node
+}
+
+// xpathStep selects the children, or the descendants, of the current nodes named name (any
+// name for *) and optionally only the index-th of them
+type xpathStep struct {
+	descendant bool
+	name       string
+	index      int // 1-based, 0 selects all of them
+}
+
+// XPath returns the value at path in an XML document: the text of the first matching element,
+// or the value of an attribute when the path ends with /@name. Only the child (/) and descendant
+// (//) axes with element names, * and positional predicates are supported, e.g.
+// `/order/id`, `//item[2]/sku` or `/order/@id`. A trailing /text() is ignored
+func XPath(body []byte, path string) (string, error) {
+	steps, attr, err := parseXPath(path)
+	if err != nil {
+		return "", err
+	}
+	root, err := parseXML(body)
+	if err != nil {
+		return "", err
+	}
+	nodes := []*node{root}
+	for _, step := range steps {
+		var selected []*node
+		for _, n := range nodes {
+			var matches []*node
+			collect(n, step, &matches)
+			if step.index > 0 {
+				if step.index > len(matches) {
+					continue
+				}
+				matches = matches[step.index-1 : step.index]
+			}
+			selected = append(selected, matches...)
+		}
+		nodes = selected
+	}
+	for _, n := range nodes {
+		if attr == "" {
+			return strings.TrimSpace(n.text.String()), nil
+		}
+		if value, ok := n.attrs[attr]; ok {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("no match for %s", path)
+}
+
+func collect(n *node, step xpathStep, matches *[]*node) {
+	for _, child := range n.children {
+		if step.name == "*" || child.name == step.name {
+			*matches = append(*matches, child)
+		}
+		if step.descendant {
+			collect(child, step, matches)
+		}
+	}
+}
+
+func parseXPath(path string) ([]xpathStep, string, error) {
+	rest := strings.TrimSuffix(strings.TrimSpace(path), "/text()")
+	if !strings.HasPrefix(rest, "/") {
+		return nil, "", fmt.Errorf("invalid xpath %q, expected it to start with /", path)
+	}
+	var steps []xpathStep
+	var attr string
+	for rest != "" {
+		step := xpathStep{}
+		if strings.HasPrefix(rest, "//") {
+			step.descendant = true
+			rest = rest[2:]
+		} else {
+			rest = rest[1:]
+		}
+		end := strings.IndexByte(rest, '/')
+		if end < 0 {
+			end = len(rest)
+		}
+		name := rest[:end]
+		rest = rest[end:]
+		if attrName, ok := strings.CutPrefix(name, "@"); ok {
+			if rest != "" || attrName == "" {
+				return nil, "", fmt.Errorf("invalid xpath %q, an attribute must be last", path)
+			}
+			attr = attrName
+			break
+		}
+		if open := strings.IndexByte(name, '['); open >= 0 {
+			if !strings.HasSuffix(name, "]") {
+				return nil, "", fmt.Errorf("invalid xpath %q, missing ]", path)
+			}
+			index, err := strconv.Atoi(name[open+1 : len(name)-1])
+			if err != nil || index < 1 {
+				return nil, "", fmt.Errorf("invalid xpath %q, only positions from 1 are supported as predicates", path)
+			}
+			step.index = index
+			name = name[:open]
+		}
+		if name == "" {
+			return nil, "", fmt.Errorf("invalid xpath %q, empty step", path)
+		}
+		step.name = name
+		steps = append(steps, step)
+	}
+	return steps, attr, nil
+}
+
+// parseXML reads a document into a tree whose root is above the document element
+func parseXML(body []byte) (*node, error) {
+	root := &node{}
+	stack := []*node{root}
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid xml document error=[%v]", err)
+		}
+		current := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			n := &node{name: t.Name.Local, attrs: make(map[string]string, len(t.Attr))}
+			for _, a := range t.Attr {
+				n.attrs[a.Name.Local] = a.Value
+			}
+			current.children = append(current.children, n)
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			current.text.Write(t)
+		}
+	}
+	if len(root.children) == 0 {
+		return nil, fmt.Errorf("invalid xml document, no element")
+	}
+	return root, nil
+}
@@ -0,0 +1,52 @@
+package extract
+
+import "testing"
+
+const orderXML = `<?xml version="1.0"?>
+<order id="ORD-1">
+  <status>PAID</status>
+  <items>
+    <item sku="BOOK"><quantity>2</quantity></item>
+    <item sku="PEN"><quantity> 10 </quantity></item>
+  </items>
+</order>`
+
+func TestXPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{path: "/order/status", want: "PAID"},
+		{path: "/order/status/text()", want: "PAID"},
+		{path: "/order/@id", want: "ORD-1"},
+		{path: "//item[2]/quantity", want: "10"},
+		{path: "//item[2]/@sku", want: "PEN"},
+		{path: "/order/items/item/@sku", want: "BOOK"},
+		{path: "/order/*/item[1]/quantity", want: "2"},
+		{path: "//quantity", want: "2"},
+		{path: "//item[3]", wantErr: true},
+		{path: "/order/total", wantErr: true},
+		{path: "/order/@currency", wantErr: true},
+		{path: "/order/@id/status", wantErr: true},
+		{path: "//item[0]", wantErr: true},
+		{path: "//item[first]", wantErr: true},
+		{path: "order/status", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := XPath([]byte(orderXML), tt.path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("XPath(%s) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("XPath(%s) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestXPathInvalidDocument(t *testing.T) {
+	if _, err := XPath([]byte(`<order><id>1</order>`), "/order/id"); err == nil {
+		t.Error("XPath() of an invalid document = nil error, want one")
+	}
+}
@@ -0,0 +1,160 @@
+package feeder
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/rk1165/loadsimulator/internal/assets"
+	"github.com/rk1165/loadsimulator/internal/load"
+	"github.com/rk1165/loadsimulator/internal/types"
+)
+
+// Feeder hands out the rows of a file to the requests of a run
+type Feeder struct {
+	file     string
+	strategy string
+	rows     []map[string]string
+	next     atomic.Uint64
+	workers  []atomic.Uint64 // next row of the share of every worker with FeederUnique
+}
+
+// New reads the rows of the file of the feeder. workers is the number of workers of the run, the
+// rows are split between them with FeederUnique
+func New(cfg types.FeederConfig, workers int) (*Feeder, error) {
+	f := &Feeder{file: cfg.File, strategy: cfg.Strategy}
+	if f.strategy == "" {
+		f.strategy = types.FeederCircular
+	}
+	switch f.strategy {
+	case types.FeederCircular, types.FeederRandom, types.FeederSequential, types.FeederUnique:
+	default:
+		return nil, fmt.Errorf("invalid feeder strategy %q, expected %s, %s, %s or %s", f.strategy,
+			types.FeederCircular, types.FeederRandom, types.FeederSequential, types.FeederUnique)
+	}
+	b, err := assets.Data.ReadFile(cfg.File)
+	if err != nil {
+		return nil, fmt.Errorf("feeder: %v", err)
+	}
+	switch strings.ToLower(filepath.Ext(cfg.File)) {
+	case ".csv":
+		f.rows, err = readCSV(b)
+	case ".jsonl", ".ndjson":
+		f.rows, err = readJSONL(b)
+	default:
+		return nil, fmt.Errorf("feeder %s: expected a .csv, .jsonl or .ndjson file", cfg.File)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("feeder %s: %v", cfg.File, err)
+	}
+	if len(f.rows) == 0 {
+		return nil, fmt.Errorf("feeder %s: no rows", cfg.File)
+	}
+	if f.strategy == types.FeederUnique {
+		if workers < 1 || len(f.rows) < workers {
+			return nil, fmt.Errorf("feeder %s: %d rows for %d workers, a unique feeder needs a row per worker at least",
+				cfg.File, len(f.rows), workers)
+		}
+		f.workers = make([]atomic.Uint64, workers)
+	}
+	return f, nil
+}
+
+// Next returns the row of a request, whose context tells the worker executing it. It returns
+// load.ErrExhausted once a sequential feeder handed out all its rows, or a unique feeder all the
+// rows of the worker
+func (f *Feeder) Next(ctx context.Context) (map[string]string, error) {
+	switch f.strategy {
+	case types.FeederRandom:
+		return f.rows[rand.IntN(len(f.rows))], nil
+	case types.FeederSequential:
+		n := f.next.Add(1) - 1
+		if n >= uint64(len(f.rows)) {
+			return nil, fmt.Errorf("feeder %s: %w after %d rows", f.file, load.ErrExhausted, len(f.rows))
+		}
+		return f.rows[n], nil
+	case types.FeederUnique:
+		worker, _ := load.Worker(ctx)
+		return f.unique(worker)
+	default:
+		n := f.next.Add(1) - 1
+		return f.rows[n%uint64(len(f.rows))], nil
+	}
+}
+
+// unique returns the next row of the share of a worker, the rows worker, worker+workers,
+// worker+2*workers... A request executed by no worker takes the rows of the first one
+func (f *Feeder) unique(worker int) (map[string]string, error) {
+	if worker < 0 || worker >= len(f.workers) {
+		worker = 0
+	}
+	share := (len(f.rows)-worker-1)/len(f.workers) + 1
+	n := f.workers[worker].Add(1) - 1
+	if n >= uint64(share) {
+		return nil, fmt.Errorf("feeder %s: %w after the %d rows of worker %d", f.file, load.ErrExhausted, share, worker)
+	}
+	return f.rows[worker+int(n)*len(f.workers)], nil
+}
+
+func (f *Feeder) String() string {
+	return fmt.Sprintf("file=%s strategy=%s rows=%d", f.file, f.strategy, len(f.rows))
+}
+
+// readCSV reads the rows of a CSV file whose first line names the columns
+func readCSV(b []byte) ([]map[string]string, error) {
+	reader := csv.NewReader(bytes.NewReader(b))
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no header line")
+	}
+	columns := records[0]
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(columns))
+		for i, column := range columns {
+			row[strings.TrimSpace(column)] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// readJSONL reads the rows of a file of JSON objects, one per line. A value which is not a string
+// is kept as JSON
+func readJSONL(b []byte) ([]map[string]string, error) {
+	var rows []map[string]string
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(text, &object); err != nil {
+			return nil, fmt.Errorf("line %d: expected a JSON object error=[%v]", line, err)
+		}
+		row := make(map[string]string, len(object))
+		for key, raw := range object {
+			var s string
+			if json.Unmarshal(raw, &s) == nil {
+				row[key] = s
+			} else {
+				row[key] = string(raw)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, scanner.Err()
+}
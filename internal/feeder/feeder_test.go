@@ -0,0 +1,201 @@
+package feeder
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/rk1165/loadsimulator/internal/load"
+	"github.com/rk1165/loadsimulator/internal/types"
+)
+
+// write writes content to a file named name in a temporary directory and returns its path
+func write(t *testing.T, name, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "csv",
+			file:    "orders.csv",
+			content: "orderNumber, customerId ,note\n1001,C1,plain\n1002, C2,\"with, a comma and \"\"quotes\"\"\"\n",
+			want: []map[string]string{
+				{"orderNumber": "1001", "customerId": "C1", "note": "plain"},
+				{"orderNumber": "1002", "customerId": "C2", "note": `with, a comma and "quotes"`},
+			},
+		},
+		{name: "csv without rows", file: "orders.csv", content: "orderNumber,customerId\n", wantErr: true},
+		{name: "empty csv", file: "orders.csv", content: "", wantErr: true},
+		{name: "csv missing a column", file: "orders.csv", content: "orderNumber,customerId\n1001\n", wantErr: true},
+		{name: "csv unterminated quote", file: "orders.csv", content: "orderNumber\n\"1001\n", wantErr: true},
+		{
+			name:    "jsonl",
+			file:    "orders.jsonl",
+			content: "{\"orderNumber\": \"1001\", \"quantity\": 2, \"gift\": true}\n\n{\"orderNumber\": \"1002\", \"items\": [1, 2]}\n",
+			want: []map[string]string{
+				{"orderNumber": "1001", "quantity": "2", "gift": "true"},
+				{"orderNumber": "1002", "items": "[1, 2]"},
+			},
+		},
+		{name: "ndjson", file: "orders.ndjson", content: `{"orderNumber": "1001"}`, want: []map[string]string{{"orderNumber": "1001"}}},
+		{name: "jsonl not an object", file: "orders.jsonl", content: "{\"orderNumber\": \"1001\"}\n[1001]\n", wantErr: true},
+		{name: "empty jsonl", file: "orders.jsonl", content: "\n\n", wantErr: true},
+		{name: "unknown extension", file: "orders.txt", content: "orderNumber\n1001\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := New(types.FeederConfig{File: write(t, tt.file, tt.content)}, 1)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(f.rows, tt.want) {
+				t.Errorf("New() rows = %v, want %v", f.rows, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	file := write(t, "orders.csv", "orderNumber\n1\n2\n")
+	if _, err := New(types.FeederConfig{File: file, Strategy: "shuffled"}, 1); err == nil {
+		t.Error("New() with an unknown strategy = nil error, want one")
+	}
+	if _, err := New(types.FeederConfig{File: file, Strategy: types.FeederUnique}, 3); err == nil {
+		t.Error("New() unique with fewer rows than workers = nil error, want one")
+	}
+	if _, err := New(types.FeederConfig{File: filepath.Join(t.TempDir(), "missing.csv")}, 1); err == nil {
+		t.Error("New() of a missing file = nil error, want one")
+	}
+}
+
+// orders returns a feeder of rows whose orderNumber is their index
+func orders(t *testing.T, rows int, strategy string, workers int) *Feeder {
+	t.Helper()
+	content := "orderNumber\n"
+	for i := range rows {
+		content += string(rune('a'+i)) + "\n"
+	}
+	f, err := New(types.FeederConfig{File: write(t, "orders.csv", content), Strategy: strategy}, workers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// take returns the orderNumber of the next n rows, of the worker with the unique strategy,
+// stopping at the first error
+func take(f *Feeder, worker, n int) (string, error) {
+	var taken string
+	for range n {
+		var row map[string]string
+		var err error
+		if f.strategy == types.FeederUnique {
+			row, err = f.unique(worker)
+		} else {
+			row, err = f.Next(context.Background())
+		}
+		if err != nil {
+			return taken, err
+		}
+		taken += row["orderNumber"]
+	}
+	return taken, nil
+}
+
+func TestNextCircular(t *testing.T) {
+	for _, strategy := range []string{"", types.FeederCircular} {
+		if got, err := take(orders(t, 3, strategy, 1), 0, 7); err != nil || got != "abcabca" {
+			t.Errorf("Next() %q = %q, %v, want abcabca", strategy, got, err)
+		}
+	}
+}
+
+func TestNextSequential(t *testing.T) {
+	f := orders(t, 3, types.FeederSequential, 1)
+	got, err := take(f, 0, 5)
+	if got != "abc" || !errors.Is(err, load.ErrExhausted) {
+		t.Errorf("Next() = %q, %v, want abc then %v", got, err, load.ErrExhausted)
+	}
+	// it stays exhausted
+	if _, err := f.Next(context.Background()); !errors.Is(err, load.ErrExhausted) {
+		t.Errorf("Next() = %v, want %v", err, load.ErrExhausted)
+	}
+}
+
+func TestNextRandom(t *testing.T) {
+	f := orders(t, 3, types.FeederRandom, 1)
+	seen := make(map[string]bool)
+	for range 100 {
+		row, err := f.Next(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[row["orderNumber"]] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("Next() picked %v, want every row of a, b and c", seen)
+	}
+}
+
+func TestNextUnique(t *testing.T) {
+	tests := []struct {
+		rows    int
+		workers int
+		shares  []string // the rows of every worker
+	}{
+		{rows: 1, workers: 1, shares: []string{"a"}},
+		{rows: 5, workers: 1, shares: []string{"abcde"}},
+		{rows: 6, workers: 2, shares: []string{"ace", "bdf"}},
+		{rows: 7, workers: 3, shares: []string{"adg", "be", "cf"}},
+		{rows: 4, workers: 4, shares: []string{"a", "b", "c", "d"}},
+	}
+	for _, tt := range tests {
+		f := orders(t, tt.rows, types.FeederUnique, tt.workers)
+		used := make(map[rune]bool)
+		for worker, share := range tt.shares {
+			got, err := take(f, worker, tt.rows+1)
+			if got != share || !errors.Is(err, load.ErrExhausted) {
+				t.Errorf("%d rows, %d workers: Next() of worker %d = %q, %v, want %q then %v",
+					tt.rows, tt.workers, worker, got, err, share, load.ErrExhausted)
+			}
+			for _, r := range got {
+				if used[r] {
+					t.Errorf("%d rows, %d workers: row %c used twice", tt.rows, tt.workers, r)
+				}
+				used[r] = true
+			}
+		}
+		if len(used) != tt.rows {
+			t.Errorf("%d rows, %d workers: %d rows used, want all of them", tt.rows, tt.workers, len(used))
+		}
+	}
+}
+
+func TestNextUniqueWithoutWorker(t *testing.T) {
+	// a request executed outside of a worker takes the rows of the first one
+	f := orders(t, 4, types.FeederUnique, 2)
+	for _, want := range []string{"a", "c"} {
+		row, err := f.Next(context.Background())
+		if err != nil || row["orderNumber"] != want {
+			t.Errorf("Next() = %v, %v, want %s", row, err, want)
+		}
+	}
+	if _, err := f.Next(context.Background()); !errors.Is(err, load.ErrExhausted) {
+		t.Errorf("Next() = %v, want %v", err, load.ErrExhausted)
+	}
+}
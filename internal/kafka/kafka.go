@@ -3,13 +3,17 @@ package kafka
 import (
 	"context"
 	"crypto/tls"
+	"errors"
+	"fmt"
 	"os"
 	"time"
 
 	"github.com/rk1165/loadsimulator/internal"
 	"github.com/rk1165/loadsimulator/internal/load"
 	"github.com/rk1165/loadsimulator/internal/logger"
+	"github.com/rk1165/loadsimulator/internal/payload"
 	"github.com/rk1165/loadsimulator/internal/types"
+	"github.com/twmb/franz-go/pkg/kerr"
 	"github.com/twmb/franz-go/pkg/kgo"
 	"github.com/twmb/franz-go/pkg/sasl"
 	"github.com/twmb/franz-go/pkg/sasl/oauth"
@@ -18,13 +22,17 @@ import (
 
 type LoadKafka struct {
 	load.BaseLoad
-	log    load.Log
-	topic  string
-	client *kgo.Client
-	body   string
+	log      load.Log
+	topic    string
+	client   *kgo.Client
+	payloads *payload.Pool[string]
 }
 
-func NewKafka(kafkaConfig types.KafkaConfig, cfg types.Config) *LoadKafka {
+func NewKafka(kafkaConfig types.KafkaConfig, cfg types.Config) (*LoadKafka, error) {
+	payloads, err := payload.New(kafkaConfig.BaseConfig, cfg, payload.Text)
+	if err != nil {
+		return nil, err
+	}
 	var mechanism sasl.Mechanism
 	if kafkaConfig.Authentication == "oauth" {
 		mechanism = getOauthMechanism(kafkaConfig)
@@ -53,19 +61,18 @@ func NewKafka(kafkaConfig types.KafkaConfig, cfg types.Config) *LoadKafka {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err = client.Ping(ctx); err != nil {
-		logger.ErrorLog.Printf("Kafka broker unreachable: %v", err)
-		return nil
+		return nil, fmt.Errorf("kafka broker unreachable: %v", err)
 	}
 
 	kafkaLoad := &LoadKafka{
 		BaseLoad: load.NewBaseLoad(cfg),
 		client:   client,
 		log:      logger.CreateLoadLog(cfg.Name),
-		body:     kafkaConfig.ResolveBody(),
+		payloads: payloads,
 		topic:    kafkaConfig.Topic,
 	}
-	kafkaLoad.log.InfoLog.Printf("Initialized KafkaLoad configs successfully")
-	return kafkaLoad
+	kafkaLoad.log.InfoLog.Printf("Initialized KafkaLoad configs successfully payloads=[%s]", payloads)
+	return kafkaLoad, nil
 }
 
 func getOauthMechanism(kafkaConfig types.KafkaConfig) sasl.Mechanism {
@@ -92,20 +99,20 @@ func getScramMechanism(kafkaConfig types.KafkaConfig) sasl.Mechanism {
 }
 
 func (k *LoadKafka) Execute(ctx context.Context, id uint64) error {
+	variant, body := k.payloads.Pick()
 	start := time.Now()
 	rec := &kgo.Record{
 		Topic: k.topic,
-		Value: []byte(k.body),
+		Value: []byte(body),
 	}
 	results := k.client.ProduceSync(ctx, rec)
 	duration := time.Since(start)
-	if k.Success(results) {
-		k.Record(duration, true)
-		k.log.InfoLog.Printf("[Kafka Producer] requestId=%d partition=%d offset=%d", id, results[0].Record.Partition, results[0].Record.Offset)
-	} else {
-		k.Record(duration, false)
-		k.log.ErrorLog.Printf("[Kafka Producer] requestId=%d", id)
+	if !k.Success(results) {
+		return categorized(results.FirstErr())
 	}
+	k.Record(duration, true)
+	variant.Stats.Record(duration, true)
+	k.log.InfoLog.Printf("[Kafka Producer] requestId=%d partition=%d offset=%d", id, results[0].Record.Partition, results[0].Record.Offset)
 	return nil
 }
 
@@ -117,7 +124,19 @@ func (k *LoadKafka) Success(response any) bool {
 	return true
 }
 
+// categorized tags a produce error of the broker with its error code, other errors are left to
+// load.Categorize
+func categorized(err error) error {
+	var kafkaErr *kerr.Error
+	if errors.As(err, &kafkaErr) {
+		return load.WithCategory("kafka "+kafkaErr.Message, err)
+	}
+	return err
+}
+
 func (k *LoadKafka) CalculateStats() *load.Stats {
 	defer k.client.Close()
-	return k.BaseLoad.CalculateStats()
+	stats := k.BaseLoad.CalculateStats()
+	stats.Variants = k.payloads.Stats()
+	return stats
 }
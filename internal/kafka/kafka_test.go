@@ -0,0 +1,32 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/rk1165/loadsimulator/internal/load"
+	"github.com/twmb/franz-go/pkg/kerr"
+)
+
+func TestCategorized(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{kerr.NotLeaderForPartition, "kafka NOT_LEADER_FOR_PARTITION"},
+		{fmt.Errorf("produce: %w", kerr.RequestTimedOut), "kafka REQUEST_TIMED_OUT"},
+		{context.Canceled, load.CategoryOther},
+		{context.DeadlineExceeded, load.CategoryTimeout},
+	}
+	for _, tt := range tests {
+		err := categorized(tt.err)
+		if got := load.Categorize(err); got != tt.want {
+			t.Errorf("Categorize(categorized(%v)) = %q, want %q", tt.err, got, tt.want)
+		}
+		if !errors.Is(err, tt.err) {
+			t.Errorf("categorized(%v) = %v, want it to wrap the error", tt.err, err)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package load
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rk1165/loadsimulator/internal/threshold"
+	"github.com/rk1165/loadsimulator/internal/types"
+)
+
+// abortCondition stops the run when its condition holds over the last window of the run
+type abortCondition struct {
+	condition threshold.Threshold
+	window    time.Duration
+}
+
+// AbortError is the cause of the cancellation of a run stopped by an abort condition
+type AbortError struct {
+	Result threshold.Result
+	Window time.Duration
+}
+
+func (e *AbortError) Error() string {
+	return fmt.Sprintf("condition %q held over the last %s (actual=%.3f limit=%.3f)",
+		e.Result.Expression, e.Window, e.Result.Actual, e.Result.Expected)
+}
+
+func parseAbortConditions(conditions []types.AbortCondition, reportInterval time.Duration) ([]abortCondition, error) {
+	var aborts []abortCondition
+	for _, c := range conditions {
+		t, err := threshold.Parse(c.Condition)
+		if err != nil {
+			return nil, fmt.Errorf("invalid abort condition: %w", err)
+		}
+		if c.Window < 0 {
+			return nil, fmt.Errorf("invalid abort condition %q: window must be >= 0", c.Condition)
+		}
+		window := c.Window
+		if window == 0 {
+			window = reportInterval
+		}
+		aborts = append(aborts, abortCondition{condition: t, window: window})
+	}
+	return aborts, nil
+}
+
+// maxAbortWindows returns the number of reporting windows the longest abort window spans
+func (r *Runner) maxAbortWindows(every time.Duration) int {
+	n := 1
+	for _, a := range r.aborts {
+		n = max(n, windowsIn(a.window, every))
+	}
+	return n
+}
+
+func windowsIn(window, every time.Duration) int {
+	return max(1, int((window+every-1)/every))
+}
+
+// recentSamples keeps the samples of the last windows of a run. The windows are reused once
+// reported, so their histograms are copied into histograms allocated once for the whole run
+type recentSamples struct {
+	ring     []sample
+	next     int        // position of the next sample in ring
+	size     int        // number of samples kept
+	service  *Histogram // the merged windows of a condition
+	response *Histogram
+}
+
+func newRecentSamples(windows, precision int) *recentSamples {
+	r := &recentSamples{
+		ring:     make([]sample, windows),
+		service:  NewHistogram(precision),
+		response: NewHistogram(precision),
+	}
+	for i := range r.ring {
+		r.ring[i] = sample{service: NewHistogram(precision), response: NewHistogram(precision)}
+	}
+	return r
+}
+
+// add copies a sample in place of the oldest one
+func (r *recentSamples) add(s sample) {
+	kept := &r.ring[r.next]
+	kept.interval = s.interval
+	kept.failed = s.failed
+	kept.service.Reset()
+	if s.service != nil {
+		_ = kept.service.Merge(s.service)
+	}
+	kept.response.Reset()
+	_ = kept.response.Merge(s.response)
+	r.next = (r.next + 1) % len(r.ring)
+	r.size = min(r.size+1, len(r.ring))
+}
+
+// last returns the n most recent samples, most recent first
+func (r *recentSamples) last(n int) []*sample {
+	n = min(n, r.size)
+	samples := make([]*sample, 0, n)
+	for i := 1; i <= n; i++ {
+		samples = append(samples, &r.ring[(r.next-i+len(r.ring))%len(r.ring)])
+	}
+	return samples
+}
+
+// checkAbort evaluates the abort conditions against the recent windows and returns an AbortError
+// for the first condition which holds. A condition is only evaluated once the run lasted at least
+// its window
+func (r *Runner) checkAbort(recent *recentSamples, elapsed time.Duration) error {
+	for _, a := range r.aborts {
+		if elapsed < a.window {
+			continue
+		}
+		samples := recent.last(windowsIn(a.window, r.reportInterval()))
+		metrics := r.windowMetrics(samples, recent.service, recent.response, elapsed)
+		if metrics[threshold.Total]+metrics[threshold.Failed] == 0 {
+			continue
+		}
+		if result := a.condition.Evaluate(metrics); result.Passed {
+			return &AbortError{Result: result, Window: a.window}
+		}
+	}
+	return nil
+}
+
+// windowMetrics merges the samples into the metrics a threshold expression can refer to, the
+// latencies are merged into service and response which are reset first
+func (r *Runner) windowMetrics(samples []*sample, service, response *Histogram, elapsed time.Duration) map[string]float64 {
+	service.Reset()
+	response.Reset()
+	var success, fail, failed uint64
+	var length time.Duration
+	for _, s := range samples {
+		success += s.interval.Success
+		fail += s.interval.Fail
+		failed += s.failed
+		length += s.interval.Length
+		_ = service.Merge(s.service)
+		_ = response.Merge(s.response)
+	}
+	ms := func(d time.Duration) float64 {
+		return float64(d) / float64(time.Millisecond)
+	}
+	metrics := map[string]float64{
+		threshold.Total:       float64(success + fail - failed),
+		threshold.Success:     float64(success),
+		threshold.Fail:        float64(fail - failed),
+		threshold.Failed:      float64(failed),
+		threshold.DurationS:   length.Seconds(),
+		threshold.Min:         ms(service.Min()),
+		threshold.Avg:         ms(service.Mean()),
+		threshold.P50:         ms(service.Percentile(50)),
+		threshold.P90:         ms(service.Percentile(90)),
+		threshold.P95:         ms(service.Percentile(95)),
+		threshold.P99:         ms(service.Percentile(99)),
+		threshold.Max:         ms(service.Max()),
+		threshold.ResponseMin: ms(response.Min()),
+		threshold.ResponseAvg: ms(response.Mean()),
+		threshold.ResponseP50: ms(response.Percentile(50)),
+		threshold.ResponseP90: ms(response.Percentile(90)),
+		threshold.ResponseP95: ms(response.Percentile(95)),
+		threshold.ResponseP99: ms(response.Percentile(99)),
+		threshold.ResponseMax: ms(response.Max()),
+	}
+	if total := success + fail; total > 0 {
+		metrics[threshold.ErrorRate] = float64(fail) * 100 / float64(total)
+	}
+	if length > 0 {
+		metrics[threshold.AchievedRps] = float64(success+fail) / length.Seconds()
+	}
+	if r.Cfg.Mode != ModeClosed {
+		metrics[threshold.Target] = newProfile(r.Cfg).rateAt(elapsed)
+	}
+	return metrics
+}
+
+func (r *Runner) reportInterval() time.Duration {
+	if r.Cfg.ReportInterval > 0 {
+		return r.Cfg.ReportInterval
+	}
+	return DefaultReportInterval
+}
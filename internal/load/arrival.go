@@ -0,0 +1,83 @@
+package load
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/rk1165/loadsimulator/internal/types"
+)
+
+const (
+	ArrivalConstant = "constant" // requests evenly spaced following the profile
+	ArrivalUniform  = "uniform"  // evenly spaced requests moved by a random +/- jitter
+	ArrivalPoisson  = "poisson"  // exponentially distributed gaps between requests
+)
+
+// arrivals produces the fire times of the requests of a profile according to an inter-arrival model
+type arrivals struct {
+	model    string
+	jitter   time.Duration
+	rnd      *rand.Rand
+	profile  *profile
+	position float64 // position in the profile of the next request
+	fired    int
+}
+
+func newArrivals(cfg types.Config, p *profile) *arrivals {
+	model := cfg.Arrival
+	if model == "" {
+		model = ArrivalConstant
+		if cfg.Jitter > 0 {
+			model = ArrivalUniform
+		}
+	}
+	return &arrivals{
+		model:   model,
+		jitter:  cfg.Jitter,
+		rnd:     rand.New(rand.NewSource(cfg.Seed)),
+		profile: p,
+	}
+}
+
+// next returns the offset since the start of the simulation at which the next request
+// should fire and false once the profile is exhausted
+func (a *arrivals) next() (time.Duration, bool) {
+	switch a.model {
+	case ArrivalPoisson:
+		// the gaps of a poisson process with a unit rate are stretched by the profile
+		// which keeps the process poisson while the rate changes between stages
+		a.position += a.rnd.ExpFloat64()
+		if a.position >= a.profile.total {
+			return 0, false
+		}
+		a.fired++
+		return a.profile.offset(a.position), true
+	default:
+		if a.fired >= a.profile.requests() {
+			return 0, false
+		}
+		offset := a.profile.offset(float64(a.fired))
+		a.fired++
+		if a.model == ArrivalUniform && a.jitter > 0 {
+			offset += time.Duration(a.rnd.Int63n(2*int64(a.jitter)+1)) - a.jitter
+			if offset < 0 {
+				offset = 0
+			}
+		}
+		return offset, true
+	}
+}
+
+func validateArrival(cfg types.Config) error {
+	switch cfg.Arrival {
+	case "", ArrivalConstant, ArrivalUniform, ArrivalPoisson:
+	default:
+		return fmt.Errorf("unknown arrival model %q", cfg.Arrival)
+	}
+	if cfg.Jitter < 0 {
+		return errors.New("jitter must be >= 0")
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package load
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/rk1165/loadsimulator/internal/types"
+)
+
+// offsets returns the fire times of all the requests of an arrival model
+func offsets(cfg types.Config) []time.Duration {
+	a := newArrivals(cfg, newProfile(cfg))
+	var all []time.Duration
+	for {
+		offset, ok := a.next()
+		if !ok {
+			return all
+		}
+		all = append(all, offset)
+	}
+}
+
+func TestArrivalConstant(t *testing.T) {
+	got := offsets(types.Config{RatePerSec: 4, Duration: 5})
+	if len(got) != 20 {
+		t.Fatalf("got %d requests, want 20", len(got))
+	}
+	for i, offset := range got {
+		if want := time.Duration(i) * 250 * time.Millisecond; offset != want {
+			t.Errorf("request %d fires at %s, want %s", i, offset, want)
+		}
+	}
+}
+
+func TestArrivalUniform(t *testing.T) {
+	jitter := 100 * time.Millisecond
+	cfg := types.Config{RatePerSec: 4, Duration: 5, Arrival: ArrivalUniform, Jitter: jitter, Seed: 42}
+	got := offsets(cfg)
+	if len(got) != 20 {
+		t.Fatalf("got %d requests, want 20", len(got))
+	}
+	moved := 0
+	for i, offset := range got {
+		even := time.Duration(i) * 250 * time.Millisecond
+		if offset < max(even-jitter, 0) || offset > even+jitter {
+			t.Errorf("request %d fires at %s, more than %s away from %s", i, offset, jitter, even)
+		}
+		if offset != even {
+			moved++
+		}
+	}
+	if moved == 0 {
+		t.Error("no request was moved by the jitter")
+	}
+	// a jitter without a model picks the uniform one
+	cfg.Arrival = ""
+	if again := offsets(cfg); !slices.Equal(again, got) {
+		t.Error("a jitter without an arrival model is not uniform with the same seed")
+	}
+}
+
+func TestArrivalPoisson(t *testing.T) {
+	cfg := types.Config{RatePerSec: 100, Duration: 20, Arrival: ArrivalPoisson, Seed: 7}
+	got := offsets(cfg)
+	// the count of a poisson process of mean 2000 is within 4 standard deviations of it
+	if want := 2000.0; math.Abs(float64(len(got))-want) > 4*math.Sqrt(want) {
+		t.Errorf("got %d requests, want about %v", len(got), want)
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i] < got[i-1] {
+			t.Fatalf("request %d fires at %s before request %d at %s", i, got[i], i-1, got[i-1])
+		}
+	}
+	if last := got[len(got)-1]; last > 20*time.Second {
+		t.Errorf("last request fires at %s after the end of the profile", last)
+	}
+	if again := offsets(cfg); !slices.Equal(again, got) {
+		t.Error("the same seed gave different arrivals")
+	}
+}
+
+func TestValidateArrival(t *testing.T) {
+	tests := []struct {
+		cfg     types.Config
+		wantErr bool
+	}{
+		{types.Config{}, false},
+		{types.Config{Arrival: ArrivalPoisson}, false},
+		{types.Config{Arrival: ArrivalUniform, Jitter: time.Second}, false},
+		{types.Config{Arrival: "bursty"}, true},
+		{types.Config{Jitter: -time.Second}, true},
+	}
+	for _, tt := range tests {
+		if err := validateArrival(tt.cfg); (err != nil) != tt.wantErr {
+			t.Errorf("validateArrival(%+v) error = %v, wantErr %v", tt.cfg, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package load
+
+import "sync"
+
+// AssertionCount counts the responses an assertion passed and failed on
+type AssertionCount struct {
+	Passed uint64
+	Failed uint64
+}
+
+// assertionCounts counts the outcomes of assertions by name
+type assertionCounts struct {
+	mu     sync.Mutex
+	counts map[string]*AssertionCount
+}
+
+func (a *assertionCounts) add(name string, passed bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.counts == nil {
+		a.counts = make(map[string]*AssertionCount)
+	}
+	count, ok := a.counts[name]
+	if !ok {
+		count = &AssertionCount{}
+		a.counts[name] = count
+	}
+	if passed {
+		count.Passed++
+	} else {
+		count.Failed++
+	}
+}
+
+func (a *assertionCounts) snapshot() map[string]AssertionCount {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.counts) == 0 {
+		return nil
+	}
+	counts := make(map[string]AssertionCount, len(a.counts))
+	for name, count := range a.counts {
+		counts[name] = *count
+	}
+	return counts
+}
@@ -0,0 +1,168 @@
+package load
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+const (
+	ErrorPolicyFail   = "fail"   // any request whose Execute returned an error fails the run
+	ErrorPolicyIgnore = "ignore" // errors are only counted, thresholds decide whether the run passed
+)
+
+// Categories of the failures which are not specific to a load. Loads add their own ones such
+// as "http 503", "aws throttling" or "kafka NOT_LEADER_FOR_PARTITION"
+const (
+	CategoryTimeout           = "timeout"
+	CategoryConnectionRefused = "connection refused"
+	CategoryConnectionReset   = "connection reset"
+	CategoryDNS               = "dns"
+	CategoryTLS               = "tls"
+	CategoryEOF               = "eof"
+	CategoryOther             = "other"
+)
+
+// ErrorCount counts the failures of a category and keeps the message of the first one
+type ErrorCount struct {
+	Count  uint64
+	Sample string
+}
+
+// categorizedError is an error a load already put in a category
+type categorizedError struct {
+	category string
+	err      error
+}
+
+func (e *categorizedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *categorizedError) Unwrap() error {
+	return e.err
+}
+
+// WithCategory tags err with category so that the runner counts it in that category
+func WithCategory(category string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &categorizedError{category: category, err: err}
+}
+
+// Categorize returns the category of an error returned by Execute or by the client of a load,
+// the one it was tagged with by WithCategory if any
+func Categorize(err error) string {
+	var categorized *categorizedError
+	var dnsErr *net.DNSError
+	var tlsErr tls.RecordHeaderError
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &categorized):
+		return categorized.category
+	case errors.As(err, &dnsErr):
+		return CategoryDNS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return CategoryConnectionRefused
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE):
+		return CategoryConnectionReset
+	case errors.As(err, &tlsErr), errors.As(err, &certErr), errors.As(err, &unknownAuthority),
+		errors.As(err, &hostnameErr), strings.Contains(err.Error(), "tls: "):
+		return CategoryTLS
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return CategoryTimeout
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return CategoryEOF
+	default:
+		return CategoryOther
+	}
+}
+
+// errorCounts counts failures by category
+type errorCounts struct {
+	mu     sync.Mutex
+	counts map[string]*ErrorCount
+}
+
+func (e *errorCounts) add(category, message string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.counts == nil {
+		e.counts = make(map[string]*ErrorCount)
+	}
+	count, ok := e.counts[category]
+	if !ok {
+		count = &ErrorCount{Sample: message}
+		e.counts[category] = count
+	}
+	count.Count++
+}
+
+func (e *errorCounts) snapshot() map[string]ErrorCount {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.counts) == 0 {
+		return nil
+	}
+	counts := make(map[string]ErrorCount, len(e.counts))
+	for category, count := range e.counts {
+		counts[category] = *count
+	}
+	return counts
+}
+
+// mergeFailures adds the counts of from to into, keeping the sample of into when both have one
+func mergeFailures(into, from map[string]ErrorCount) map[string]ErrorCount {
+	if len(from) == 0 {
+		return into
+	}
+	if into == nil {
+		into = make(map[string]ErrorCount, len(from))
+	}
+	for category, count := range from {
+		merged := into[category]
+		merged.Count += count.Count
+		if merged.Sample == "" {
+			merged.Sample = count.Sample
+		}
+		into[category] = merged
+	}
+	return into
+}
+
+// formatFailures formats failures as `category=count` pairs sorted by category
+func formatFailures(failures map[string]ErrorCount) string {
+	keys := make([]string, 0, len(failures))
+	for category := range failures {
+		keys = append(keys, category)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, category := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%d", category, failures[category].Count))
+	}
+	return strings.Join(pairs, " ")
+}
+
+func validateErrorPolicy(policy string) error {
+	switch policy {
+	case "", ErrorPolicyFail, ErrorPolicyIgnore:
+		return nil
+	default:
+		return fmt.Errorf("unknown error policy %q, expected %s or %s", policy, ErrorPolicyFail, ErrorPolicyIgnore)
+	}
+}
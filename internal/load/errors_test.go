@@ -0,0 +1,70 @@
+package load
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+)
+
+// timeoutError is a net.Error which timed out
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// dial wraps err the way the errors of a connection are returned by an http client
+func dial(op string, err error) error {
+	return &url.Error{Op: "Get", URL: "http://localhost:8080/orders",
+		Err: &net.OpError{Op: op, Net: "tcp", Err: &os.SyscallError{Syscall: op, Err: err}}}
+}
+
+func TestCategorize(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"dns", &url.Error{Op: "Get", URL: "http://orders.invalid", Err: &net.OpError{Op: "dial", Net: "tcp",
+			Err: &net.DNSError{Err: "no such host", Name: "orders.invalid", IsNotFound: true}}}, CategoryDNS},
+		{"connection refused", dial("connect", syscall.ECONNREFUSED), CategoryConnectionRefused},
+		{"connection reset", dial("read", syscall.ECONNRESET), CategoryConnectionReset},
+		{"broken pipe", dial("write", syscall.EPIPE), CategoryConnectionReset},
+		{"tls record", fmt.Errorf("handshake: %w", tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}), CategoryTLS},
+		{"tls certificate", &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}, CategoryTLS},
+		{"unknown authority", &url.Error{Op: "Get", URL: "https://localhost", Err: x509.UnknownAuthorityError{}}, CategoryTLS},
+		{"hostname", x509.HostnameError{Certificate: &x509.Certificate{}, Host: "localhost"}, CategoryTLS},
+		{"deadline", &url.Error{Op: "Get", URL: "http://localhost", Err: context.DeadlineExceeded}, CategoryTimeout},
+		{"os deadline", fmt.Errorf("read: %w", os.ErrDeadlineExceeded), CategoryTimeout},
+		{"net timeout", &net.OpError{Op: "read", Net: "tcp", Err: timeoutError{}}, CategoryTimeout},
+		{"eof", &url.Error{Op: "Post", URL: "http://localhost", Err: io.EOF}, CategoryEOF},
+		{"unexpected eof", fmt.Errorf("read body: %w", io.ErrUnexpectedEOF), CategoryEOF},
+		{"categorized", WithCategory("http 503", io.EOF), "http 503"},
+		{"wrapped categorized", fmt.Errorf("step login: %w", WithCategory("kafka NOT_LEADER_FOR_PARTITION", errors.New("x"))),
+			"kafka NOT_LEADER_FOR_PARTITION"},
+		{"other", errors.New("unexpected response"), CategoryOther},
+	}
+	for _, tt := range tests {
+		if got := Categorize(tt.err); got != tt.want {
+			t.Errorf("Categorize(%s: %v) = %q, want %q", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestWithCategory(t *testing.T) {
+	if err := WithCategory("http 503", nil); err != nil {
+		t.Errorf("WithCategory(nil) = %v, want nil", err)
+	}
+	err := WithCategory("dns", io.EOF)
+	if !errors.Is(err, io.EOF) || err.Error() != io.EOF.Error() {
+		t.Errorf("WithCategory() = %v, want it to wrap %v", err, io.EOF)
+	}
+}
@@ -0,0 +1,231 @@
+package load
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultPrecision = 3 // significant digits kept by a histogram when none are configured
+	// MaxWindowPrecision caps the significant digits of the histograms of the reporting windows,
+	// which only feed the live metrics and the abort conditions, and of the parts of a load such
+	// as the steps of a journey. A histogram keeping 5 digits takes 16 MiB against 184 KiB for 3
+	MaxWindowPrecision = 3
+	// HighestTrackable is the largest duration a histogram tells apart, larger ones are counted as this
+	HighestTrackable = time.Hour
+)
+
+// Histogram records durations in HDR (high dynamic range) buckets: values are grouped by power of
+// two and every group is split linearly into enough sub buckets to keep the configured number of
+// significant digits. Memory is bounded by the precision and the trackable range rather than the
+// number of recorded values, and recording is lock free
+type Histogram struct {
+	precision             int
+	subBucketHalfCountMag int
+	subBucketHalfCount    int64
+	subBucketMask         int64
+	subBucketCount        int64
+	counts                []atomic.Uint64
+	total                 atomic.Uint64
+	sum                   atomic.Int64
+	min                   atomic.Int64
+	max                   atomic.Int64
+}
+
+// Bucket is a range of values of a histogram and the number of values recorded in it
+type Bucket struct {
+	From  time.Duration
+	To    time.Duration
+	Count uint64
+}
+
+// NewHistogram returns a histogram of microseconds keeping precision significant digits,
+// DefaultPrecision when 0 and at most 5
+func NewHistogram(precision int) *Histogram {
+	if precision <= 0 {
+		precision = DefaultPrecision
+	}
+	precision = min(precision, 5)
+	largestSingleUnit := 2 * int64(math.Pow10(precision))
+	subBucketCountMag := int(math.Ceil(math.Log2(float64(largestSingleUnit))))
+	h := &Histogram{
+		precision:             precision,
+		subBucketHalfCountMag: subBucketCountMag - 1,
+		subBucketCount:        1 << subBucketCountMag,
+	}
+	h.subBucketHalfCount = h.subBucketCount / 2
+	h.subBucketMask = h.subBucketCount - 1
+
+	// number of power of two buckets needed to reach the highest trackable value
+	highest := HighestTrackable.Microseconds()
+	bucketCount := 1
+	for smallestUntrackable := h.subBucketCount; smallestUntrackable <= highest; smallestUntrackable <<= 1 {
+		bucketCount++
+	}
+	h.counts = make([]atomic.Uint64, int64(bucketCount+1)*h.subBucketHalfCount)
+	h.min.Store(math.MaxInt64)
+	return h
+}
+
+// windowPrecision is the precision of the histograms of the reporting windows
+func windowPrecision(precision int) int {
+	if precision <= 0 {
+		return DefaultPrecision
+	}
+	return min(precision, MaxWindowPrecision)
+}
+
+// Reset clears the recorded values so that the histogram can be reused
+func (h *Histogram) Reset() {
+	for i := range h.counts {
+		h.counts[i].Store(0)
+	}
+	h.total.Store(0)
+	h.sum.Store(0)
+	h.min.Store(math.MaxInt64)
+	h.max.Store(0)
+}
+
+// Record adds a duration to the histogram
+func (h *Histogram) Record(d time.Duration) {
+	v := min(max(d.Microseconds(), 0), HighestTrackable.Microseconds())
+	h.counts[h.index(v)].Add(1)
+	h.total.Add(1)
+	h.sum.Add(v)
+	h.updateMinMax(v, v)
+}
+
+// Merge adds the values recorded in other, which must have the same precision, to the histogram
+func (h *Histogram) Merge(other *Histogram) error {
+	if other.precision != h.precision {
+		return errors.New("cannot merge histograms of different precision")
+	}
+	for i := range other.counts {
+		if c := other.counts[i].Load(); c > 0 {
+			h.counts[i].Add(c)
+		}
+	}
+	h.total.Add(other.total.Load())
+	h.sum.Add(other.sum.Load())
+	h.updateMinMax(other.min.Load(), other.max.Load())
+	return nil
+}
+
+func (h *Histogram) updateMinMax(lo, hi int64) {
+	for cur := h.min.Load(); lo < cur && !h.min.CompareAndSwap(cur, lo); cur = h.min.Load() {
+	}
+	for cur := h.max.Load(); hi > cur && !h.max.CompareAndSwap(cur, hi); cur = h.max.Load() {
+	}
+}
+
+// Count returns the number of recorded values
+func (h *Histogram) Count() uint64 {
+	return h.total.Load()
+}
+
+// Percentile returns the value below which p percent of the recorded values fall
+func (h *Histogram) Percentile(p float64) time.Duration {
+	total := h.total.Load()
+	if total == 0 {
+		return 0
+	}
+	target := uint64(math.Ceil(p / 100 * float64(total)))
+	target = min(max(target, 1), total)
+	var seen uint64
+	for i := range h.counts {
+		seen += h.counts[i].Load()
+		if seen >= target {
+			v := h.highestEquivalent(h.valueAt(i))
+			return time.Duration(min(v, h.max.Load())) * time.Microsecond
+		}
+	}
+	return h.Max()
+}
+
+// Min returns the smallest recorded value
+func (h *Histogram) Min() time.Duration {
+	if h.total.Load() == 0 {
+		return 0
+	}
+	return time.Duration(h.min.Load()) * time.Microsecond
+}
+
+// Max returns the largest recorded value
+func (h *Histogram) Max() time.Duration {
+	return time.Duration(h.max.Load()) * time.Microsecond
+}
+
+// Mean returns the average of the recorded values
+func (h *Histogram) Mean() time.Duration {
+	total := h.total.Load()
+	if total == 0 {
+		return 0
+	}
+	return time.Duration(h.sum.Load()/int64(total)) * time.Microsecond
+}
+
+// Latency returns the distribution of the recorded values
+func (h *Histogram) Latency() Latency {
+	return Latency{
+		MinTime: h.Min(),
+		AvgTime: h.Mean(),
+		P50:     h.Percentile(50),
+		P90:     h.Percentile(90),
+		P95:     h.Percentile(95),
+		P99:     h.Percentile(99),
+		MaxTime: h.Max(),
+	}
+}
+
+// Buckets returns the non-empty buckets of the histogram in increasing order
+func (h *Histogram) Buckets() []Bucket {
+	var buckets []Bucket
+	for i := range h.counts {
+		if c := h.counts[i].Load(); c > 0 {
+			v := h.valueAt(i)
+			buckets = append(buckets, Bucket{
+				From:  time.Duration(v) * time.Microsecond,
+				To:    time.Duration(h.highestEquivalent(v)) * time.Microsecond,
+				Count: c,
+			})
+		}
+	}
+	return buckets
+}
+
+// index returns the position in counts of the bucket holding v
+func (h *Histogram) index(v int64) int {
+	bucket := h.bucketIndex(v)
+	subBucket := v >> bucket
+	return int((int64(bucket)+1)<<h.subBucketHalfCountMag + subBucket - h.subBucketHalfCount)
+}
+
+func (h *Histogram) bucketIndex(v int64) int {
+	// smallest power of two containing v, relative to the sub bucket range
+	return max(64-bits.LeadingZeros64(uint64(v|h.subBucketMask))-(h.subBucketHalfCountMag+1), 0)
+}
+
+// valueAt returns the lowest value of the bucket at position i in counts
+func (h *Histogram) valueAt(i int) int64 {
+	bucket := i>>h.subBucketHalfCountMag - 1
+	subBucket := int64(i)&(h.subBucketHalfCount-1) + h.subBucketHalfCount
+	if bucket < 0 {
+		subBucket -= h.subBucketHalfCount
+		bucket = 0
+	}
+	return subBucket << bucket
+}
+
+// highestEquivalent returns the highest value counted in the same bucket as v
+func (h *Histogram) highestEquivalent(v int64) int64 {
+	bucket := h.bucketIndex(v)
+	subBucket := v >> bucket
+	size := int64(1) << bucket
+	if subBucket >= h.subBucketCount {
+		size <<= 1
+	}
+	return (v &^ (size - 1)) + size - 1
+}
@@ -0,0 +1,122 @@
+package load
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// within tells if got is within precision significant digits of want
+func within(got, want time.Duration, precision int) bool {
+	return float64((got - want).Abs()) <= float64(want)*math.Pow10(-precision)
+}
+
+func TestHistogramPercentiles(t *testing.T) {
+	for _, precision := range []int{1, 2, 3, 4} {
+		h := NewHistogram(precision)
+		for i := 1; i <= 10000; i++ {
+			h.Record(time.Duration(i) * time.Millisecond)
+		}
+		if h.Count() != 10000 {
+			t.Fatalf("precision %d: Count() = %d, want 10000", precision, h.Count())
+		}
+		if h.Min() != time.Millisecond || h.Max() != 10*time.Second {
+			t.Errorf("precision %d: min=%s max=%s, want 1ms and 10s", precision, h.Min(), h.Max())
+		}
+		if want := 5000500 * time.Microsecond; h.Mean() != want {
+			t.Errorf("precision %d: Mean() = %s, want %s", precision, h.Mean(), want)
+		}
+		for _, p := range []float64{25, 50, 75, 90, 95, 99} {
+			want := time.Duration(p*100) * time.Millisecond
+			if got := h.Percentile(p); !within(got, want, precision) {
+				t.Errorf("precision %d: Percentile(%v) = %s, want %s", precision, p, got, want)
+			}
+		}
+		if got := h.Percentile(100); got != h.Max() {
+			t.Errorf("precision %d: Percentile(100) = %s, want the max %s", precision, got, h.Max())
+		}
+	}
+}
+
+func TestHistogramEmpty(t *testing.T) {
+	h := NewHistogram(0)
+	if l := h.Latency(); l != (Latency{}) {
+		t.Errorf("Latency() = %+v, want zeros", l)
+	}
+	if b := h.Buckets(); len(b) != 0 {
+		t.Errorf("Buckets() = %v, want none", b)
+	}
+}
+
+func TestHistogramClampsValues(t *testing.T) {
+	h := NewHistogram(3)
+	h.Record(-time.Second)
+	h.Record(2 * HighestTrackable)
+	if h.Min() != 0 {
+		t.Errorf("Min() = %s, want 0", h.Min())
+	}
+	if h.Max() != HighestTrackable {
+		t.Errorf("Max() = %s, want %s", h.Max(), HighestTrackable)
+	}
+}
+
+func TestHistogramMerge(t *testing.T) {
+	whole, low, high := NewHistogram(3), NewHistogram(3), NewHistogram(3)
+	for i := 1; i <= 2000; i++ {
+		d := time.Duration(i) * 137 * time.Microsecond
+		whole.Record(d)
+		if i%2 == 0 {
+			low.Record(d)
+		} else {
+			high.Record(d)
+		}
+	}
+	merged := NewHistogram(3)
+	for _, h := range []*Histogram{low, high} {
+		if err := merged.Merge(h); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if merged.Latency() != whole.Latency() {
+		t.Errorf("merged latency %+v, want %+v", merged.Latency(), whole.Latency())
+	}
+	if merged.Count() != whole.Count() {
+		t.Errorf("merged count %d, want %d", merged.Count(), whole.Count())
+	}
+	if err := merged.Merge(NewHistogram(2)); err == nil {
+		t.Error("merging histograms of different precision did not fail")
+	}
+}
+
+func TestHistogramBuckets(t *testing.T) {
+	h := NewHistogram(2)
+	values := []time.Duration{time.Millisecond, time.Millisecond, 50 * time.Millisecond, time.Second}
+	for _, v := range values {
+		h.Record(v)
+	}
+	var total uint64
+	var previous time.Duration
+	for _, b := range h.Buckets() {
+		if b.From < previous || b.To < b.From {
+			t.Errorf("bucket %+v out of order", b)
+		}
+		previous = b.To
+		total += b.Count
+	}
+	if total != uint64(len(values)) {
+		t.Errorf("buckets count %d values, want %d", total, len(values))
+	}
+}
+
+func TestHistogramReset(t *testing.T) {
+	h := NewHistogram(3)
+	h.Record(time.Second)
+	h.Reset()
+	if h.Count() != 0 || h.Latency() != (Latency{}) {
+		t.Errorf("after Reset count=%d latency=%+v, want nothing", h.Count(), h.Latency())
+	}
+	h.Record(time.Millisecond)
+	if h.Min() != time.Millisecond || h.Max() != time.Millisecond {
+		t.Errorf("after Reset min=%s max=%s, want 1ms", h.Min(), h.Max())
+	}
+}
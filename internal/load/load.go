@@ -2,9 +2,8 @@ package load
 
 import (
 	"context"
+	"fmt"
 	"log"
-	"sort"
-	"sync"
 	"sync/atomic"
 	"time"
 
@@ -12,9 +11,73 @@ import (
 )
 
 type Stats struct {
-	Success uint64
-	Fail    uint64
-	Total   uint64
+	Success      uint64
+	Fail         uint64
+	Total        uint64
+	ServiceTime  Latency                   // measured by the load around the call to the target
+	ResponseTime Latency                   // measured by the runner from the time the request was scheduled to fire
+	Histogram    []Bucket                  // distribution of the service times
+	Errors       uint64                    // requests whose Execute returned an error, they are not part of Total
+	Failures     map[string]ErrorCount     // failed responses and execute errors by category
+	Aborted      string                    // reason the run was stopped early, if it was
+	Interrupted  bool                      // the run was stopped by the caller before its end
+	Exhausted    bool                      // the run was stopped early as the data of the load was exhausted
+	Steps        []StepStats               // stats of every step of a load made of several steps
+	Variants     []StepStats               // stats of every payload variant of a load sending several, they add up to Total
+	Assertions   map[string]AssertionCount // outcomes of the assertions on the responses by assertion
+	latencies    *Histogram
+}
+
+// StepStats are the stats of a named part of a load, a step of a journey or a payload variant
+type StepStats struct {
+	Name  string
+	Stats *Stats
+}
+
+// PartStats records the responses of a named part of a load, a step of a journey or a payload
+// variant. A load may have many parts, so unlike a BaseLoad it has no reporting windows and its
+// histogram keeps at most MaxWindowPrecision digits
+type PartStats struct {
+	ok           atomic.Uint64
+	ko           atomic.Uint64
+	serviceTimes *Histogram
+	failures     errorCounts
+}
+
+func NewPartStats(cfg types.Config) *PartStats {
+	return &PartStats{serviceTimes: NewHistogram(windowPrecision(cfg.HistogramPrecision))}
+}
+
+// Record records a response, a failed one is counted in the "other" category
+func (p *PartStats) Record(responseTime time.Duration, ok bool) {
+	if !ok {
+		p.RecordFailure(responseTime, CategoryOther, "")
+		return
+	}
+	p.ok.Add(1)
+	p.serviceTimes.Record(responseTime)
+}
+
+// RecordFailure records a failed response in category, message is kept as a sample of the category
+func (p *PartStats) RecordFailure(responseTime time.Duration, category, message string) {
+	p.ko.Add(1)
+	p.serviceTimes.Record(responseTime)
+	p.failures.add(category, message)
+}
+
+func (p *PartStats) CalculateStats() *Stats {
+	ok, ko := p.ok.Load(), p.ko.Load()
+	return &Stats{
+		Total:       ok + ko,
+		Success:     ok,
+		Fail:        ko,
+		ServiceTime: p.serviceTimes.Latency(),
+		Failures:    p.failures.snapshot(),
+	}
+}
+
+// Latency is the distribution of a set of recorded durations
+type Latency struct {
 	MinTime time.Duration
 	AvgTime time.Duration
 	P50     time.Duration
@@ -32,73 +95,95 @@ type Log struct {
 type Load interface {
 	Success(response any) bool
 	CalculateStats() *Stats
+	// TakeInterval returns the stats recorded since the previous call and starts a new interval
+	TakeInterval() *Stats
 	Execute(ctx context.Context, id uint64) error
 }
 
 type BaseLoad struct {
-	Cfg           types.Config
-	OK            atomic.Uint64
-	KO            atomic.Uint64
-	Total         atomic.Uint64
-	ResponseTimes []time.Duration
-	Mu            sync.Mutex
+	Cfg          types.Config
+	OK           atomic.Uint64
+	KO           atomic.Uint64
+	Total        atomic.Uint64
+	ServiceTimes *Histogram
+	interval     *windows
+	failures     *errorCounts
+	assertions   *assertionCounts
 }
 
 func NewBaseLoad(cfg types.Config) BaseLoad {
-	expectedRequests := cfg.RatePerSec * cfg.Duration
 	return BaseLoad{
-		Cfg:           cfg,
-		ResponseTimes: make([]time.Duration, 0, expectedRequests),
-		Mu:            sync.Mutex{},
+		Cfg:          cfg,
+		ServiceTimes: NewHistogram(cfg.HistogramPrecision),
+		interval:     newWindows(cfg.HistogramPrecision),
+		failures:     &errorCounts{},
+		assertions:   &assertionCounts{},
 	}
 }
 
+// Record records a response, a failed one is counted in the "other" category
 func (b *BaseLoad) Record(responseTime time.Duration, ok bool) {
-	b.Total.Add(1)
-	if ok {
-		b.OK.Add(1)
-	} else {
-		b.KO.Add(1)
+	if !ok {
+		b.RecordFailure(responseTime, CategoryOther, "")
+		return
 	}
-	b.Mu.Lock()
-	b.ResponseTimes = append(b.ResponseTimes, responseTime)
-	b.Mu.Unlock()
+	b.Total.Add(1)
+	b.OK.Add(1)
+	b.ServiceTimes.Record(responseTime)
+	b.interval.record(responseTime, true)
+}
+
+// RecordFailure records a failed response in category, message is kept as a sample of the category
+func (b *BaseLoad) RecordFailure(responseTime time.Duration, category, message string) {
+	b.Total.Add(1)
+	b.KO.Add(1)
+	b.ServiceTimes.Record(responseTime)
+	b.interval.record(responseTime, false)
+	b.failures.add(category, message)
+}
+
+// RecordAssertion records whether a response passed the assertion name
+func (b *BaseLoad) RecordAssertion(name string, passed bool) {
+	b.assertions.add(name, passed)
 }
 
 func (b *BaseLoad) CalculateStats() *Stats {
-	total := b.Total.Load()
-	ok := b.OK.Load()
-	ko := b.KO.Load()
-	if len(b.ResponseTimes) == 0 {
-		return &Stats{
-			Total:   total,
-			Success: ok,
-			Fail:    ko,
-		}
+	return &Stats{
+		Total:       b.Total.Load(),
+		Success:     b.OK.Load(),
+		Fail:        b.KO.Load(),
+		ServiceTime: b.ServiceTimes.Latency(),
+		Histogram:   b.ServiceTimes.Buckets(),
+		Failures:    b.failures.snapshot(),
+		Assertions:  b.assertions.snapshot(),
 	}
+}
 
-	sort.Slice(b.ResponseTimes, func(i, j int) bool {
-		return b.ResponseTimes[i] < b.ResponseTimes[j]
-	})
+func (b *BaseLoad) TakeInterval() *Stats {
+	w := b.interval.take()
+	ok, ko := w.ok.Load(), w.ko.Load()
+	return &Stats{
+		Total:       ok + ko,
+		Success:     ok,
+		Fail:        ko,
+		ServiceTime: w.latencies.Latency(),
+		latencies:   w.latencies,
+	}
+}
 
-	var sum time.Duration
-	for _, duration := range b.ResponseTimes {
-		sum += duration
+func (s *Stats) String() string {
+	str := fmt.Sprintf("total=%d success=%d fail=%d serviceTime={%s} responseTime={%s}",
+		s.Total, s.Success, s.Fail, s.ServiceTime, s.ResponseTime)
+	if s.Errors > 0 {
+		str += fmt.Sprintf(" errors=%d", s.Errors)
 	}
-	n := len(b.ResponseTimes)
-	avg := sum / time.Duration(n)
-
-	stats := &Stats{
-		Total:   total,
-		Success: ok,
-		Fail:    ko,
-		MinTime: b.ResponseTimes[0],
-		P50:     b.ResponseTimes[n/2],
-		AvgTime: avg,
-		P90:     b.ResponseTimes[int(float64(n)*0.9)],
-		P95:     b.ResponseTimes[int(float64(n)*0.95)],
-		P99:     b.ResponseTimes[int(float64(n)*0.99)],
-		MaxTime: b.ResponseTimes[n-1],
+	if len(s.Failures) > 0 {
+		str += fmt.Sprintf(" failures={%s}", formatFailures(s.Failures))
 	}
-	return stats
+	return str
+}
+
+func (l Latency) String() string {
+	return fmt.Sprintf("min=%s avg=%s p50=%s p90=%s p95=%s p99=%s max=%s",
+		l.MinTime, l.AvgTime, l.P50, l.P90, l.P95, l.P99, l.MaxTime)
 }
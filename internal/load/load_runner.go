@@ -4,18 +4,30 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand"
 	"runtime"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/rk1165/loadsimulator/internal/threshold"
 	"github.com/rk1165/loadsimulator/internal/types"
 )
 
 const MaxConcurrency = 100
 
-// Runner runs a task at a fixed rate (requests per second) for a duration
+// DefaultGracePeriod is how long requests in flight are given to complete when a run is interrupted
+const DefaultGracePeriod = 10 * time.Second
+
+const (
+	ModeOpen   = "open"   // requests are fired at the rate of the profile whatever the latency of the target
+	ModeClosed = "closed" // every virtual user fires its next request once the previous one completed
+)
+
+// Runner runs a task at a fixed rate (requests per second) for a duration, or following
+// the rates of the configured stages
 // Concurrency >= RPS * averageLatencySeconds (Little's Law)
+// In closed mode Concurrency virtual users execute the task back to back instead
 type Runner struct {
 	Load           Load
 	Cfg            types.Config
@@ -23,108 +35,334 @@ type Runner struct {
 	startedCount   uint64
 	completedCount uint64
 	failedCount    uint64
+	cancelledCount uint64
 	firstErr       atomic.Value
+	errOnce        sync.Once
+	errorCounts    errorCounts // requests whose Execute returned an error by category
+	// responseTimes are measured from the time a request was scheduled to fire, so unlike the
+	// service times recorded by the load they include the time spent waiting for a free worker
+	responseTimes *Histogram
+	intervals     *windows // response times of the current reporting window
+	timeline      []Interval
+	timelineMu    sync.Mutex
+	startTime     time.Time
+	elapsed       time.Duration
+	aborts        []abortCondition
+	abortedBy     error
+	interrupted   bool
+	exhausted     bool
+	stop          context.CancelCauseFunc // stops the run with ErrExhausted once the data is exhausted
+}
+
+// Summary holds the counters of the runner at the end of a run
+type Summary struct {
+	StartTime   time.Time
+	Duration    time.Duration
+	Scheduled   uint64
+	Started     uint64
+	Completed   uint64
+	Failed      uint64  // requests whose Execute returned an error
+	Cancelled   uint64  // requests in flight or scheduled but not started yet when the run was stopped
+	Throughput  float64 // completed requests per second
+	TargetRate  float64 // average rate of the profile, 0 in closed mode
+	FirstError  string
+	Errors      map[string]ErrorCount // requests whose Execute returned an error by category
+	Aborted     string                // reason the run was stopped early, if it was
+	Interrupted bool                  // the run was stopped by the caller before its end
+	Exhausted   bool                  // the run was stopped early as the data of the load was exhausted
 }
 
 func NewLoadRunner(load Load, cfg types.Config) *Runner {
-	return &Runner{Load: load, Cfg: cfg}
+	return &Runner{
+		Load:          load,
+		Cfg:           cfg,
+		responseTimes: NewHistogram(cfg.HistogramPrecision),
+		intervals:     newWindows(cfg.HistogramPrecision),
+	}
 }
 
-// Run executes the Execute method of a load at the configured rate for the given duration
-// Returns earliest error (if any)
-func (r *Runner) Run(ctx context.Context, statCh chan<- *Stats) error {
+// Run executes the Execute method of a load at the configured rate for the given duration,
+// or with the configured virtual users in closed mode
+// Cancelling ctx stops the scheduling of new requests, the requests in flight are given
+// cfg.GracePeriod to complete and stats are still sent for what ran
+// Requests whose Execute returned an error are counted as failures and the stats are sent
+// on statCh whatever the outcome, statCh is closed without stats when the config is invalid
+// Returns the earliest error when requests failed and the error policy is to fail the run
+func (r *Runner) Run(parent context.Context, statCh chan<- *Stats) error {
+	defer close(statCh)
 	if err := r.ValidateConfig(); err != nil {
 		return err
 	}
+	if r.Cfg.Seed == 0 {
+		r.Cfg.Seed = time.Now().UnixNano()
+	}
 	cfg := r.Cfg
-	cfg.InfoLog.Printf("[INIT LOAD CONFIG] rps=%d duration=%d concurrency=%d jitter=%s", cfg.RatePerSec, cfg.Duration, cfg.Concurrency, cfg.Jitter)
+	r.aborts, _ = parseAbortConditions(cfg.Abort, r.reportInterval())
 
-	// loadCh receives request by the scheduler to execute a load every 'firing' second
-	// where 'firing' second is calculated based on rps and duration and jitter (if any)
-	loadCh := make(chan time.Time, cfg.Concurrency)
+	// ctx stops the scheduler and the workers, it is cancelled by the caller or by an abort condition
+	ctx, abort := context.WithCancelCause(parent)
+	defer abort(nil)
+	r.stop = abort
+	// execCtx is the context of the requests in flight which outlive ctx to drain gracefully
+	execCtx, cancelExec := context.WithCancel(context.WithoutCancel(parent))
+	defer cancelExec()
+	go r.drain(ctx, parent, cancelExec)
 
 	var wg sync.WaitGroup
 	simulationStartTime := time.Now()
-	r.StartWorkers(ctx, loadCh, &wg)
+	stopReporter := make(chan struct{})
+	reporterDone := make(chan struct{})
+	go r.StartReporter(stopReporter, reporterDone, simulationStartTime, abort)
+	if cfg.Mode == ModeClosed {
+		cfg.InfoLog.Printf("[INIT LOAD CONFIG] mode=%s virtualUsers=%d duration=%d iterations=%d thinkTime=%+v seed=%d",
+			cfg.Mode, cfg.Concurrency, cfg.Duration, cfg.Iterations, cfg.ThinkTime, cfg.Seed)
+		r.StartVirtualUsers(ctx, execCtx, &wg, simulationStartTime)
+	} else {
+		cfg.InfoLog.Printf("[INIT LOAD CONFIG] rps=%d duration=%d concurrency=%d arrival=%s jitter=%s seed=%d stages=%v",
+			cfg.RatePerSec, cfg.Duration, cfg.Concurrency, cfg.Arrival, cfg.Jitter, cfg.Seed, cfg.Stages)
 
-	go r.StartScheduler(ctx, loadCh, simulationStartTime)
+		// loadCh receives request by the scheduler to execute a load every 'firing' second
+		// where 'firing' second is calculated based on rps and duration and jitter (if any)
+		loadCh := make(chan time.Time, cfg.Concurrency)
+		r.StartWorkers(ctx, execCtx, loadCh, &wg)
+
+		go r.StartScheduler(ctx, loadCh, simulationStartTime)
+	}
 
 	wg.Wait()
-	cfg.InfoLog.Printf("[SUMMARY] scheduled=%d started=%d completed=%d failures=%d duration=%s",
-		atomic.LoadUint64(&r.scheduledCount),
-		atomic.LoadUint64(&r.startedCount),
-		atomic.LoadUint64(&r.completedCount),
-		atomic.LoadUint64(&r.failedCount),
-		time.Since(simulationStartTime).Truncate(time.Millisecond))
+	close(stopReporter)
+	<-reporterDone
+	r.startTime = simulationStartTime
+	r.elapsed = time.Since(simulationStartTime)
+	if cause := context.Cause(ctx); cause != nil {
+		var abortErr *AbortError
+		if errors.As(cause, &abortErr) {
+			r.abortedBy = abortErr
+			cfg.ErrorLog.Printf("[ABORTED] run stopped after %s: %v", r.elapsed.Truncate(time.Millisecond), abortErr)
+		} else if errors.Is(cause, ErrExhausted) {
+			r.exhausted = true
+			cfg.InfoLog.Printf("[EXHAUSTED] run stopped after %s, the data is exhausted", r.elapsed.Truncate(time.Millisecond))
+		} else if parent.Err() != nil {
+			r.interrupted = true
+			cfg.ErrorLog.Printf("[INTERRUPTED] run stopped after %s", r.elapsed.Truncate(time.Millisecond))
+		}
+	}
+	summary := r.Summary()
+	cfg.InfoLog.Printf("[SUMMARY] scheduled=%d started=%d completed=%d failures=%d cancelled=%d duration=%s throughput=%.1f/s",
+		summary.Scheduled,
+		summary.Started,
+		summary.Completed,
+		summary.Failed,
+		summary.Cancelled,
+		summary.Duration.Truncate(time.Millisecond),
+		summary.Throughput)
+	if summary.Errors != nil {
+		cfg.ErrorLog.Printf("[SUMMARY] errors %s", formatFailures(summary.Errors))
+	}
 	cfg.InfoLog.Println("-------------------------------------------------------------------------")
-	if v := r.firstErr.Load(); v != nil {
-		return fmt.Errorf("%w", v.(error))
+	stats := r.Load.CalculateStats()
+	stats.ResponseTime = r.responseTimes.Latency()
+	stats.Errors = summary.Failed
+	stats.Failures = mergeFailures(stats.Failures, summary.Errors)
+	if r.abortedBy != nil {
+		stats.Aborted = r.abortedBy.Error()
+	}
+	stats.Interrupted = r.interrupted
+	stats.Exhausted = r.exhausted
+	statCh <- stats
+	if v := r.firstErr.Load(); v != nil && cfg.ErrorPolicy != ErrorPolicyIgnore {
+		return fmt.Errorf("%d request(s) failed, first error: %w", summary.Failed, v.(error))
 	}
 	cfg.InfoLog.Println("Load Run completed successfully")
-	statCh <- r.Load.CalculateStats()
-	close(statCh)
 	return nil
 }
 
+// Summary returns the counters of the runner, the duration is only known once Run returned
+func (r *Runner) Summary() Summary {
+	summary := Summary{
+		StartTime: r.startTime,
+		Duration:  r.elapsed,
+		Scheduled: atomic.LoadUint64(&r.scheduledCount),
+		Started:   atomic.LoadUint64(&r.startedCount),
+		Completed: atomic.LoadUint64(&r.completedCount),
+		Failed:    atomic.LoadUint64(&r.failedCount),
+		Cancelled: atomic.LoadUint64(&r.cancelledCount),
+		Errors:    r.errorCounts.snapshot(),
+	}
+	if r.abortedBy != nil {
+		summary.Aborted = r.abortedBy.Error()
+	}
+	summary.Interrupted = r.interrupted
+	summary.Exhausted = r.exhausted
+	if r.elapsed > 0 {
+		summary.Throughput = float64(summary.Completed) / r.elapsed.Seconds()
+	}
+	if r.Cfg.Mode != ModeClosed {
+		if p := newProfile(r.Cfg); p.duration > 0 {
+			summary.TargetRate = p.total / p.duration.Seconds()
+		}
+	}
+	if v := r.firstErr.Load(); v != nil {
+		summary.FirstError = v.(error).Error()
+	}
+	return summary
+}
+
+// drain waits for the run to stop. Requests in flight are cancelled right away when the run was
+// aborted and after cfg.GracePeriod when the caller cancelled parent
+func (r *Runner) drain(ctx, parent context.Context, cancelExec context.CancelFunc) {
+	<-ctx.Done()
+	var abortErr *AbortError
+	if errors.As(context.Cause(ctx), &abortErr) {
+		cancelExec()
+		return
+	}
+	if parent.Err() != nil {
+		grace := r.Cfg.GracePeriod
+		if grace <= 0 {
+			grace = DefaultGracePeriod
+		}
+		r.Cfg.InfoLog.Printf("[INTERRUPTED] stop scheduling, draining requests in flight for up to %s", grace)
+		time.AfterFunc(grace, cancelExec)
+	}
+}
+
 // StartWorkers starts cfg.Concurrency number of workers for executing the load received on loadCh
-func (r *Runner) StartWorkers(ctx context.Context, loadCh <-chan time.Time, wg *sync.WaitGroup) {
+// Workers stop executing requests once ctx is done, the requests themselves run with execCtx
+func (r *Runner) StartWorkers(ctx, execCtx context.Context, loadCh <-chan time.Time, wg *sync.WaitGroup) {
 	cfg := r.Cfg
-	var errOnce sync.Once
 
 	for i := 0; i < cfg.Concurrency; i++ {
 		wg.Add(1)
-		go func(workerID string) {
+		go func(workerID string, workerCtx context.Context) {
 			defer wg.Done()
 			for scheduled := range loadCh {
-				started := time.Now()
-				offset := started.Sub(scheduled) // difference between scheduled and started time
-				requestId := atomic.AddUint64(&r.startedCount, 1)
-				cfg.InfoLog.Printf("workerID=[%s] [START] requestID=%d offset=%s goroutines=%d",
-					workerID, requestId, offset, runtime.NumGoroutine())
-				if e := r.Load.Execute(ctx, requestId); e != nil {
-					errOnce.Do(func() { r.firstErr.Store(e) })
-					atomic.AddUint64(&r.failedCount, 1)
-					cfg.ErrorLog.Printf("workerId=[%s] [FAIL] requestId=%d err=[%v]", workerID, requestId, e)
-				} else {
-					cfg.InfoLog.Printf("workerId=[%s] [DONE] requestId=%d elapsed=%s", workerID, requestId, time.Since(started))
+				if ctx.Err() != nil {
+					// drain what was scheduled before the run was stopped, it is dropped
+					atomic.AddUint64(&r.cancelledCount, 1)
+					continue
+				}
+				r.execute(workerCtx, workerID, scheduled)
+			}
+		}(fmt.Sprintf("%s-%d", cfg.Name, i), withWorker(execCtx, i))
+	}
+}
+
+// StartVirtualUsers starts cfg.Concurrency virtual users, each executing the load back to back
+// with the configured think time in between, until the duration or the iterations are exhausted
+// or ctx is done, the requests themselves run with execCtx
+func (r *Runner) StartVirtualUsers(ctx, execCtx context.Context, wg *sync.WaitGroup, simulationStartTime time.Time) {
+	cfg := r.Cfg
+	var deadline time.Time
+	if cfg.Duration > 0 {
+		deadline = simulationStartTime.Add(time.Duration(cfg.Duration) * time.Second)
+	}
+
+	for i := 0; i < cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func(workerID string, workerCtx context.Context, rnd *rand.Rand) {
+			defer wg.Done()
+			for iteration := 0; cfg.Iterations == 0 || iteration < cfg.Iterations; iteration++ {
+				now := time.Now()
+				if (!deadline.IsZero() && !now.Before(deadline)) || ctx.Err() != nil {
+					return
+				}
+				atomic.AddUint64(&r.scheduledCount, 1)
+				r.execute(workerCtx, workerID, now)
+
+				pause := thinkTime(cfg.ThinkTime, rnd)
+				if !deadline.IsZero() {
+					pause = min(pause, time.Until(deadline))
+				}
+				select {
+				case <-time.After(pause):
+				case <-ctx.Done():
+					cfg.InfoLog.Printf("workerId=[%s] [STOP] context cancelled after %d iterations", workerID, iteration+1)
+					return
 				}
-				atomic.AddUint64(&r.completedCount, 1)
 			}
-		}(fmt.Sprintf("%s-%d", cfg.Name, i))
+		}(fmt.Sprintf("%s-%d", cfg.Name, i), withWorker(execCtx, i), rand.New(rand.NewSource(cfg.Seed+int64(i))))
+	}
+}
+
+// execute runs the load once for a request which was scheduled to fire at scheduled
+func (r *Runner) execute(ctx context.Context, workerID string, scheduled time.Time) {
+	cfg := r.Cfg
+	started := time.Now()
+	offset := started.Sub(scheduled) // difference between scheduled and started time
+	requestId := atomic.AddUint64(&r.startedCount, 1)
+	cfg.InfoLog.Printf("workerID=[%s] [START] requestID=%d offset=%s goroutines=%d",
+		workerID, requestId, offset, runtime.NumGoroutine())
+	if e := r.Load.Execute(ctx, requestId); e != nil && ctx.Err() != nil {
+		// the run was stopped while the request was in flight
+		atomic.AddUint64(&r.cancelledCount, 1)
+		cfg.ErrorLog.Printf("workerId=[%s] [CANCELLED] requestId=%d err=[%v]", workerID, requestId, e)
+		return
+	} else if errors.Is(e, ErrExhausted) {
+		// the request did not run, nor will the next ones
+		atomic.AddUint64(&r.cancelledCount, 1)
+		cfg.InfoLog.Printf("workerId=[%s] [EXHAUSTED] requestId=%d err=[%v]", workerID, requestId, e)
+		if r.stop != nil {
+			r.stop(ErrExhausted)
+		}
+		return
+	} else if e != nil {
+		r.errOnce.Do(func() { r.firstErr.Store(e) })
+		atomic.AddUint64(&r.failedCount, 1)
+		r.errorCounts.add(Categorize(e), e.Error())
+		r.intervals.recordFailure()
+		cfg.ErrorLog.Printf("workerId=[%s] [FAIL] requestId=%d err=[%v]", workerID, requestId, e)
+	} else {
+		responseTime := time.Since(scheduled)
+		r.responseTimes.Record(responseTime)
+		r.intervals.record(responseTime, true)
+		cfg.InfoLog.Printf("workerId=[%s] [DONE] requestId=%d elapsed=%s responseTime=%s",
+			workerID, requestId, time.Since(started), responseTime)
+	}
+	atomic.AddUint64(&r.completedCount, 1)
+}
+
+// thinkTime returns the pause of a virtual user between two iterations
+func thinkTime(t types.ThinkTime, rnd *rand.Rand) time.Duration {
+	if t.Max <= t.Min {
+		return t.Min
 	}
+	return t.Min + time.Duration(rnd.Int63n(int64(t.Max-t.Min)+1))
 }
 
 // StartScheduler starts a scheduler for scheduling the load on the workers
+// The fire time of every request is computed from the load profile and the arrival model of the config
 func (r *Runner) StartScheduler(ctx context.Context, loadCh chan time.Time, simulationStartTime time.Time) {
 	cfg := r.Cfg
-	interval := time.Second / time.Duration(cfg.RatePerSec) // frequency of firing requests
-	totalRequests := cfg.RatePerSec * cfg.Duration
+	p := newProfile(cfg)
+	a := newArrivals(cfg, p)
 	defer close(loadCh)
 	n := 0
-	next := simulationStartTime
-	//rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
-	for n < totalRequests {
-		fire := next
-		//if cfg.Jitter > 0 {
-		//	j := time.Duration(rnd.Int63n(int64(cfg.Jitter)))
-		//	if rnd.Intn(2) == 0 {
-		//		fire = fire.Add(-j)
-		//	} else {
-		//		fire = fire.Add(j)
-		//	}
-		//}
+	lastProgress := simulationStartTime
+	for {
+		offset, ok := a.next()
+		if !ok {
+			break
+		}
+		fire := simulationStartTime.Add(offset)
 		if d := time.Until(fire); d > 0 {
-			time.Sleep(d)
+			select {
+			case <-time.After(d):
+			case <-ctx.Done():
+				cfg.InfoLog.Printf("[SCHEDULER] stop: context cancelled after %d requests", n)
+				return
+			}
 		}
 		select {
 		case loadCh <- fire:
 			n++
 			atomic.AddUint64(&r.scheduledCount, 1)
-			next = simulationStartTime.Add(time.Duration(n) * interval)
-			if n%cfg.RatePerSec == 0 {
+			if time.Since(lastProgress) >= time.Second {
+				lastProgress = time.Now()
 				elapsed := time.Since(simulationStartTime)
-				cfg.InfoLog.Printf("[PROGRESS] elapsed=%s scheduled=%d started=%d completed=%d failures=%d",
+				cfg.InfoLog.Printf("[PROGRESS] elapsed=%s rate=%.1f scheduled=%d started=%d completed=%d failures=%d",
 					elapsed.Truncate(time.Millisecond),
+					p.rateAt(elapsed),
 					atomic.LoadUint64(&r.scheduledCount),
 					atomic.LoadUint64(&r.startedCount),
 					atomic.LoadUint64(&r.completedCount),
@@ -141,11 +379,29 @@ func (r *Runner) StartScheduler(ctx context.Context, loadCh chan time.Time, simu
 
 func (r *Runner) ValidateConfig() error {
 	cfg := r.Cfg
-	if cfg.RatePerSec <= 0 {
-		return errors.New("RPS must be > 0")
+	switch cfg.Mode {
+	case "", ModeOpen:
+		if err := validateOpen(cfg); err != nil {
+			return err
+		}
+	case ModeClosed:
+		if err := validateClosed(cfg); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown mode %q", cfg.Mode)
+	}
+	if err := validateErrorPolicy(cfg.ErrorPolicy); err != nil {
+		return err
+	}
+	if _, err := threshold.ParseAll(cfg.Thresholds); err != nil {
+		return err
+	}
+	if _, err := parseAbortConditions(cfg.Abort, r.reportInterval()); err != nil {
+		return err
 	}
-	if cfg.Duration <= 0 {
-		return errors.New("duration must be > 0")
+	if cfg.HistogramPrecision < 0 || cfg.HistogramPrecision > 5 {
+		return errors.New("histogram precision must be between 1 and 5, or 0 for the default")
 	}
 	if cfg.Concurrency <= 0 {
 		return errors.New("concurrency must be > 0")
@@ -155,3 +411,52 @@ func (r *Runner) ValidateConfig() error {
 	}
 	return nil
 }
+
+func validateOpen(cfg types.Config) error {
+	if len(cfg.Stages) > 0 {
+		if err := validateStages(cfg.Stages); err != nil {
+			return err
+		}
+	} else {
+		if cfg.RatePerSec <= 0 {
+			return errors.New("RPS must be > 0")
+		}
+		if cfg.Duration <= 0 {
+			return errors.New("duration must be > 0")
+		}
+	}
+	return validateArrival(cfg)
+}
+
+func validateClosed(cfg types.Config) error {
+	if cfg.Duration < 0 || cfg.Iterations < 0 {
+		return errors.New("duration and iterations must be >= 0")
+	}
+	if cfg.Duration == 0 && cfg.Iterations == 0 {
+		return errors.New("closed mode needs a duration or iterations")
+	}
+	if cfg.ThinkTime.Min < 0 || cfg.ThinkTime.Max < 0 {
+		return errors.New("think time must be >= 0")
+	}
+	if cfg.ThinkTime.Max > 0 && cfg.ThinkTime.Max < cfg.ThinkTime.Min {
+		return errors.New("think time max must be >= min")
+	}
+	return nil
+}
+
+func validateStages(stages []types.Stage) error {
+	total := 0
+	for i, stage := range stages {
+		if stage.Duration < 0 {
+			return fmt.Errorf("stage %d: duration must be >= 0", i)
+		}
+		if stage.Target < 0 {
+			return fmt.Errorf("stage %d: target must be >= 0", i)
+		}
+		total += stage.Duration
+	}
+	if total <= 0 {
+		return errors.New("stages must last > 0 seconds")
+	}
+	return nil
+}
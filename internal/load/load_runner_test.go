@@ -0,0 +1,223 @@
+package load
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rk1165/loadsimulator/internal/types"
+)
+
+// fakeLoad executes execute for every request, its responses are recorded by execute itself
+type fakeLoad struct {
+	BaseLoad
+	execute func(ctx context.Context, l *fakeLoad, id uint64) error
+}
+
+func (f *fakeLoad) Success(response any) bool {
+	return true
+}
+
+func (f *fakeLoad) Execute(ctx context.Context, id uint64) error {
+	return f.execute(ctx, f, id)
+}
+
+// succeed records a successful response of latency
+func succeed(latency time.Duration) func(ctx context.Context, l *fakeLoad, id uint64) error {
+	return func(ctx context.Context, l *fakeLoad, id uint64) error {
+		time.Sleep(latency)
+		l.Record(latency, true)
+		return nil
+	}
+}
+
+// block waits for the request to be cancelled
+func block(ctx context.Context, l *fakeLoad, id uint64) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func testConfig(t *testing.T, cfg types.Config) types.Config {
+	cfg.Name = "test"
+	cfg.ReportInterval = 20 * time.Millisecond
+	cfg.TimeSeriesFile = filepath.Join(t.TempDir(), "timeseries.csv")
+	cfg.InfoLog = log.New(io.Discard, "", 0)
+	cfg.ErrorLog = log.New(io.Discard, "", 0)
+	return cfg
+}
+
+// run runs the load and returns the error of the run and the stats it sent
+func run(t *testing.T, ctx context.Context, cfg types.Config,
+	execute func(ctx context.Context, l *fakeLoad, id uint64) error) (*Runner, *Stats, error) {
+	t.Helper()
+	cfg = testConfig(t, cfg)
+	runner := NewLoadRunner(&fakeLoad{BaseLoad: NewBaseLoad(cfg), execute: execute}, cfg)
+	ch := make(chan *Stats, 1)
+	err := runner.Run(ctx, ch)
+	stats, ok := <-ch
+	if !ok {
+		t.Fatalf("Run() error = %v, no stats sent", err)
+	}
+	return runner, stats, err
+}
+
+func TestRunOpen(t *testing.T) {
+	runner, stats, err := run(t, context.Background(),
+		types.Config{RatePerSec: 20, Duration: 1, Concurrency: 2}, succeed(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary := runner.Summary()
+	if summary.Scheduled != 20 || summary.Completed != 20 || summary.Cancelled != 0 {
+		t.Errorf("Summary() = %+v, want 20 requests scheduled and completed", summary)
+	}
+	if stats.Total != 20 || stats.Success != 20 || stats.Interrupted || stats.Aborted != "" {
+		t.Errorf("stats = %v, want 20 successful requests", stats)
+	}
+}
+
+func TestRunInvalidConfig(t *testing.T) {
+	cfg := testConfig(t, types.Config{RatePerSec: 10, Duration: 1})
+	runner := NewLoadRunner(&fakeLoad{BaseLoad: NewBaseLoad(cfg), execute: succeed(0)}, cfg)
+	ch := make(chan *Stats, 1)
+	if err := runner.Run(context.Background(), ch); err == nil {
+		t.Error("Run() = nil error, want concurrency must be > 0")
+	}
+	if stats, ok := <-ch; ok {
+		t.Errorf("Run() sent %v, want the channel closed without stats", stats)
+	}
+}
+
+func TestRunErrorPolicy(t *testing.T) {
+	failure := errors.New("connection lost")
+	fail := func(ctx context.Context, l *fakeLoad, id uint64) error {
+		if id%2 == 0 {
+			return failure
+		}
+		l.Record(time.Millisecond, true)
+		return nil
+	}
+	for _, policy := range []string{"", ErrorPolicyFail, ErrorPolicyIgnore} {
+		t.Run(policy, func(t *testing.T) {
+			runner, stats, err := run(t, context.Background(),
+				types.Config{Mode: ModeClosed, Iterations: 4, Concurrency: 1, ErrorPolicy: policy}, fail)
+			if policy == ErrorPolicyIgnore {
+				if err != nil {
+					t.Errorf("Run() error = %v, want nil with the ignore policy", err)
+				}
+			} else if !errors.Is(err, failure) {
+				t.Errorf("Run() error = %v, want %v", err, failure)
+			}
+			// the stats are sent whatever the policy
+			if stats.Total != 2 || stats.Errors != 2 || stats.Failures[CategoryOther].Count != 2 {
+				t.Errorf("stats = %v, want 2 responses and 2 errors", stats)
+			}
+			if summary := runner.Summary(); summary.Completed != 4 || summary.Failed != 2 ||
+				summary.FirstError != failure.Error() {
+				t.Errorf("Summary() = %+v, want 4 completed requests of which 2 failed", summary)
+			}
+		})
+	}
+}
+
+func TestRunAbort(t *testing.T) {
+	fail := func(ctx context.Context, l *fakeLoad, id uint64) error {
+		time.Sleep(time.Millisecond)
+		l.RecordFailure(time.Millisecond, "http 503", "service unavailable")
+		return nil
+	}
+	start := time.Now()
+	_, stats, err := run(t, context.Background(), types.Config{Mode: ModeClosed, Duration: 10, Concurrency: 2,
+		Abort: []types.AbortCondition{{Condition: "errorRate > 50%", Window: 40 * time.Millisecond}}}, fail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Run() took %s, want it aborted early", elapsed)
+	}
+	if !strings.Contains(stats.Aborted, "errorRate > 50%") || stats.Interrupted {
+		t.Errorf("stats = %+v, want the run aborted by the error rate", stats)
+	}
+}
+
+func TestRunInterrupted(t *testing.T) {
+	tests := []struct {
+		name        string
+		grace       time.Duration
+		execute     func(ctx context.Context, l *fakeLoad, id uint64) error
+		wantSuccess bool // the requests in flight complete within the grace period
+	}{
+		{name: "drained", grace: time.Second, execute: succeed(100 * time.Millisecond), wantSuccess: true},
+		{name: "cancelled", grace: 20 * time.Millisecond, execute: block},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(50*time.Millisecond, cancel)
+			runner, stats, err := run(t, ctx,
+				types.Config{Mode: ModeClosed, Duration: 10, Concurrency: 2, GracePeriod: tt.grace}, tt.execute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			summary := runner.Summary()
+			if !stats.Interrupted || !summary.Interrupted {
+				t.Errorf("stats = %v, want the run interrupted", stats)
+			}
+			if summary.Scheduled != summary.Completed+summary.Cancelled {
+				t.Errorf("Summary() = %+v, want every scheduled request completed or cancelled", summary)
+			}
+			if tt.wantSuccess {
+				if summary.Cancelled != 0 || stats.Success != summary.Completed || stats.Success == 0 {
+					t.Errorf("Summary() = %+v, stats = %v, want the requests in flight completed", summary, stats)
+				}
+			} else if summary.Cancelled != 2 || summary.Completed != 0 || summary.Failed != 0 {
+				t.Errorf("Summary() = %+v, want the 2 requests in flight cancelled", summary)
+			}
+		})
+	}
+}
+
+func TestRunInterruptedOpen(t *testing.T) {
+	// a single worker blocked on a request, the requests scheduled meanwhile are dropped
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+	runner, _, err := run(t, ctx,
+		types.Config{RatePerSec: 50, Duration: 1, Concurrency: 1, GracePeriod: 20 * time.Millisecond}, block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary := runner.Summary()
+	if summary.Completed != 0 || summary.Scheduled < 2 || summary.Cancelled != summary.Scheduled {
+		t.Errorf("Summary() = %+v, want every scheduled request cancelled", summary)
+	}
+}
+
+func TestRunExhausted(t *testing.T) {
+	var executed atomic.Uint64
+	execute := func(ctx context.Context, l *fakeLoad, id uint64) error {
+		if executed.Add(1) > 5 {
+			return ErrExhausted
+		}
+		l.Record(time.Millisecond, true)
+		return nil
+	}
+	start := time.Now()
+	runner, stats, err := run(t, context.Background(),
+		types.Config{Mode: ModeClosed, Duration: 10, Concurrency: 2}, execute)
+	if err != nil {
+		t.Fatalf("Run() error = %v, an exhausted run is not a failure", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Run() took %s, want it stopped once exhausted", elapsed)
+	}
+	summary := runner.Summary()
+	if !stats.Exhausted || stats.Interrupted || stats.Success != 5 || summary.Completed != 5 || summary.Failed != 0 {
+		t.Errorf("stats = %v, Summary() = %+v, want 5 requests and the run exhausted", stats, summary)
+	}
+}
@@ -0,0 +1,96 @@
+package load
+
+import (
+	"math"
+	"time"
+
+	"github.com/rk1165/loadsimulator/internal/types"
+)
+
+// profile describes how the rate of a load changes over the simulation. It is made of
+// segments in which the rate changes linearly, a constant rate being a single flat segment
+type profile struct {
+	segments []segment
+	total    float64       // number of requests fired over the whole profile
+	duration time.Duration // length of the whole profile
+}
+
+type segment struct {
+	start  float64 // seconds since the start of the simulation
+	length float64 // seconds
+	from   float64 // rate at the start of the segment
+	to     float64 // rate at the end of the segment
+	fired  float64 // requests fired before the segment starts
+	count  float64 // requests fired within the segment
+}
+
+// newProfile builds the profile from the stages of the config, or from RatePerSec and
+// Duration when no stages are configured
+func newProfile(cfg types.Config) *profile {
+	stages := cfg.Stages
+	if len(stages) == 0 {
+		stages = []types.Stage{
+			{Duration: 0, Target: cfg.RatePerSec},
+			{Duration: cfg.Duration, Target: cfg.RatePerSec},
+		}
+	}
+	p := &profile{}
+	var start, rate float64
+	for _, stage := range stages {
+		target := float64(stage.Target)
+		length := float64(stage.Duration)
+		if length > 0 {
+			count := (rate + target) / 2 * length
+			p.segments = append(p.segments, segment{
+				start:  start,
+				length: length,
+				from:   rate,
+				to:     target,
+				fired:  p.total,
+				count:  count,
+			})
+			p.total += count
+			start += length
+		}
+		rate = target
+	}
+	p.duration = time.Duration(start * float64(time.Second))
+	return p
+}
+
+// requests returns the number of whole requests fired over the profile
+func (p *profile) requests() int {
+	return int(math.Floor(p.total + 1e-9))
+}
+
+// offset returns the time since the start of the simulation at which the request numbered
+// n (counting from 0) should fire. n can be fractional which allows arrival models to
+// place requests between the evenly spaced ones
+func (p *profile) offset(n float64) time.Duration {
+	for _, s := range p.segments {
+		if s.count <= 0 || n >= s.fired+s.count {
+			continue
+		}
+		dn := n - s.fired
+		if dn <= 0 {
+			return time.Duration(s.start * float64(time.Second))
+		}
+		// solve from*t + (to-from)/(2*length)*t^2 = dn for t, in a form which stays
+		// stable when the rate does not change within the segment
+		a := (s.to - s.from) / (2 * s.length)
+		t := 2 * dn / (s.from + math.Sqrt(s.from*s.from+4*a*dn))
+		return time.Duration((s.start + t) * float64(time.Second))
+	}
+	return p.duration
+}
+
+// rateAt returns the target rate at the given time since the start of the simulation
+func (p *profile) rateAt(elapsed time.Duration) float64 {
+	t := elapsed.Seconds()
+	for _, s := range p.segments {
+		if t < s.start+s.length {
+			return s.from + (s.to-s.from)*(t-s.start)/s.length
+		}
+	}
+	return 0
+}
@@ -0,0 +1,115 @@
+package load
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rk1165/loadsimulator/internal/types"
+)
+
+func TestProfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      types.Config
+		requests int
+		duration time.Duration
+		offsets  map[float64]time.Duration // offset of request n
+	}{
+		{
+			name:     "constant rate",
+			cfg:      types.Config{RatePerSec: 10, Duration: 2},
+			requests: 20,
+			duration: 2 * time.Second,
+			offsets:  map[float64]time.Duration{0: 0, 5: 500 * time.Millisecond, 10: time.Second, 19: 1900 * time.Millisecond},
+		},
+		{
+			name:     "ramp up from 0",
+			cfg:      types.Config{Stages: []types.Stage{{Duration: 10, Target: 10}}},
+			requests: 50,
+			duration: 10 * time.Second,
+			// t^2/2 requests are fired after t seconds
+			offsets: map[float64]time.Duration{0: 0, 12.5: 5 * time.Second, 32: 8 * time.Second, 50: 10 * time.Second},
+		},
+		{
+			name: "jump, hold and ramp down",
+			cfg: types.Config{Stages: []types.Stage{
+				{Duration: 0, Target: 20},
+				{Duration: 5, Target: 20},
+				{Duration: 4, Target: 0},
+			}},
+			requests: 140,
+			duration: 9 * time.Second,
+			offsets: map[float64]time.Duration{
+				20:  time.Second,
+				100: 5 * time.Second,
+				// 20t - 2.5t^2 requests are fired t seconds into the ramp down
+				130: 7 * time.Second,
+			},
+		},
+		{
+			name:     "empty",
+			cfg:      types.Config{},
+			requests: 0,
+			duration: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newProfile(tt.cfg)
+			if got := p.requests(); got != tt.requests {
+				t.Errorf("requests() = %d, want %d", got, tt.requests)
+			}
+			if p.duration != tt.duration {
+				t.Errorf("duration = %s, want %s", p.duration, tt.duration)
+			}
+			for n, want := range tt.offsets {
+				if got := p.offset(n); (got - want).Abs() > time.Millisecond {
+					t.Errorf("offset(%v) = %s, want %s", n, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestProfileOffsetsIncrease(t *testing.T) {
+	p := newProfile(types.Config{Stages: []types.Stage{
+		{Duration: 3, Target: 30},
+		{Duration: 2, Target: 30},
+		{Duration: 0, Target: 60},
+		{Duration: 3, Target: 0},
+	}})
+	previous := time.Duration(-1)
+	for n := 0; n < p.requests(); n++ {
+		offset := p.offset(float64(n))
+		if offset < previous {
+			t.Fatalf("offset(%d) = %s before offset(%d) = %s", n, offset, n-1, previous)
+		}
+		if offset > p.duration {
+			t.Fatalf("offset(%d) = %s after the end of the profile %s", n, offset, p.duration)
+		}
+		previous = offset
+	}
+}
+
+func TestProfileRateAt(t *testing.T) {
+	p := newProfile(types.Config{Stages: []types.Stage{
+		{Duration: 10, Target: 10},
+		{Duration: 0, Target: 50},
+		{Duration: 10, Target: 50},
+	}})
+	tests := []struct {
+		elapsed time.Duration
+		want    float64
+	}{
+		{0, 0},
+		{5 * time.Second, 5},
+		{10 * time.Second, 50},
+		{15 * time.Second, 50},
+		{25 * time.Second, 0},
+	}
+	for _, tt := range tests {
+		if got := p.rateAt(tt.elapsed); got != tt.want {
+			t.Errorf("rateAt(%s) = %v, want %v", tt.elapsed, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package load
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultReportInterval is the length of the reporting window when none is configured
+const DefaultReportInterval = time.Second
+
+// Interval holds the metrics of one reporting window of a run
+type Interval struct {
+	Elapsed      time.Duration // since the start of the run, at the end of the window
+	Length       time.Duration
+	Throughput   float64 // completed requests per second
+	Success      uint64
+	Fail         uint64
+	ErrorRate    float64 // percentage of failed requests
+	ServiceTime  Latency
+	ResponseTime Latency
+}
+
+// window accumulates the outcome of the requests completed within one reporting interval
+type window struct {
+	ok        atomic.Uint64
+	ko        atomic.Uint64
+	latencies *Histogram
+	writers   atomic.Int64 // recorders writing to the window
+}
+
+func (w *window) reset() {
+	w.ok.Store(0)
+	w.ko.Store(0)
+	w.latencies.Reset()
+}
+
+// windows hands out the current window and swaps it for the spare one at every report. The two
+// windows are reused so that reporting does not allocate histograms, a window returned by take
+// is valid until the next take. A recorder registers as a writer of the window before writing and
+// take waits for the writers of the window it swaps out, so that no value lands in a window
+// being read or reset
+type windows struct {
+	current atomic.Pointer[window]
+	spare   *window
+}
+
+func newWindows(precision int) *windows {
+	precision = windowPrecision(precision)
+	w := &windows{spare: &window{latencies: NewHistogram(precision)}}
+	w.current.Store(&window{latencies: NewHistogram(precision)})
+	return w
+}
+
+func (w *windows) record(d time.Duration, ok bool) {
+	cur := w.acquire()
+	defer cur.writers.Add(-1)
+	if ok {
+		cur.ok.Add(1)
+	} else {
+		cur.ko.Add(1)
+	}
+	cur.latencies.Record(d)
+}
+
+// recordFailure counts a failed request which has no latency
+func (w *windows) recordFailure() {
+	cur := w.acquire()
+	defer cur.writers.Add(-1)
+	cur.ko.Add(1)
+}
+
+// acquire returns the current window registered as written to, the caller releases it
+func (w *windows) acquire() *window {
+	for {
+		cur := w.current.Load()
+		cur.writers.Add(1)
+		if w.current.Load() == cur {
+			return cur
+		}
+		// taken meanwhile, record in the window which replaced it
+		cur.writers.Add(-1)
+	}
+}
+
+func (w *windows) take() *window {
+	w.spare.reset()
+	taken := w.current.Swap(w.spare)
+	w.spare = taken
+	for taken.writers.Load() > 0 {
+		runtime.Gosched()
+	}
+	return taken
+}
+
+// StartReporter reports the metrics of the last window to the console and the time series file
+// every cfg.ReportInterval until stop is closed, then reports the last partial window (if not empty) and closes done
+// The abort conditions are checked at every report and abort is called with the first which holds
+func (r *Runner) StartReporter(stop <-chan struct{}, done chan<- struct{}, simulationStartTime time.Time,
+	abort context.CancelCauseFunc) {
+	defer close(done)
+	cfg := r.Cfg
+	every := r.reportInterval()
+	file := cfg.TimeSeriesFile
+	if file == "" {
+		file = filepath.Join("logs", cfg.Name+"_timeseries.csv")
+	}
+	series, err := createTimeSeries(file)
+	if err != nil {
+		cfg.ErrorLog.Printf("[LIVE] unable to write time series to %s error=[%v]", file, err)
+	} else {
+		defer series.Close()
+	}
+
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	last := simulationStartTime
+	var recent *recentSamples // as many windows as the longest abort window needs
+	if len(r.aborts) > 0 {
+		recent = newRecentSamples(r.maxAbortWindows(every), windowPrecision(cfg.HistogramPrecision))
+	}
+	report := func(now time.Time, final bool) {
+		length := now.Sub(last)
+		if final {
+			// the last window is usually a sliver of the stragglers of the run which would
+			// show up as a spike of throughput if it was not spread over a full window
+			length = max(length, every)
+		}
+		interval, s := r.takeInterval(now.Sub(simulationStartTime), length)
+		last = now
+		if final && interval.Success+interval.Fail == 0 {
+			return
+		}
+		if recent != nil && !final {
+			recent.add(s)
+			if err := r.checkAbort(recent, now.Sub(simulationStartTime)); err != nil {
+				cfg.ErrorLog.Printf("[ABORT] %v", err)
+				abort(err)
+			}
+		}
+		r.timelineMu.Lock()
+		r.timeline = append(r.timeline, interval)
+		r.timelineMu.Unlock()
+		cfg.InfoLog.Printf("[LIVE] elapsed=%s rps=%.1f ok=%d ko=%d errors=%.2f%% p50=%s p95=%s p99=%s responseP99=%s",
+			interval.Elapsed.Truncate(time.Millisecond), interval.Throughput, interval.Success, interval.Fail,
+			interval.ErrorRate, interval.ServiceTime.P50, interval.ServiceTime.P95, interval.ServiceTime.P99,
+			interval.ResponseTime.P99)
+		if series != nil {
+			if _, err := fmt.Fprintln(series, interval.csv()); err != nil {
+				cfg.ErrorLog.Printf("[LIVE] unable to write time series to %s error=[%v]", file, err)
+			}
+		}
+	}
+	for {
+		select {
+		case now := <-ticker.C:
+			report(now, false)
+		case <-stop:
+			report(time.Now(), true)
+			return
+		}
+	}
+}
+
+// sample keeps the recorded values of a window so that several windows can be merged
+type sample struct {
+	interval Interval
+	failed   uint64 // requests whose Execute returned an error
+	service  *Histogram
+	response *Histogram
+}
+
+// takeInterval closes the current window of the load and of the runner and returns their metrics
+func (r *Runner) takeInterval(elapsed, length time.Duration) (Interval, sample) {
+	stats := r.Load.TakeInterval()
+	responses := r.intervals.take()
+	interval := Interval{
+		Elapsed:      elapsed,
+		Length:       length,
+		Success:      stats.Success,
+		Fail:         stats.Fail + responses.ko.Load(),
+		ServiceTime:  stats.ServiceTime,
+		ResponseTime: responses.latencies.Latency(),
+	}
+	total := interval.Success + interval.Fail
+	if length > 0 {
+		interval.Throughput = float64(total) / length.Seconds()
+	}
+	if total > 0 {
+		interval.ErrorRate = float64(interval.Fail) * 100 / float64(total)
+	}
+	return interval, sample{interval: interval, failed: responses.ko.Load(), service: stats.latencies,
+		response: responses.latencies}
+}
+
+// Timeline returns the metrics of every reporting window of the run so far
+func (r *Runner) Timeline() []Interval {
+	r.timelineMu.Lock()
+	defer r.timelineMu.Unlock()
+	return append([]Interval(nil), r.timeline...)
+}
+
+const timeSeriesHeader = "elapsed_s,throughput,success,fail,error_rate," +
+	"p50_ms,p95_ms,p99_ms,response_p50_ms,response_p95_ms,response_p99_ms"
+
+func createTimeSeries(file string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fmt.Fprintln(f, timeSeriesHeader); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+func (i Interval) csv() string {
+	ms := func(d time.Duration) string {
+		return fmt.Sprintf("%.3f", float64(d)/float64(time.Millisecond))
+	}
+	return fmt.Sprintf("%.3f,%.2f,%d,%d,%.2f,%s,%s,%s,%s,%s,%s",
+		i.Elapsed.Seconds(), i.Throughput, i.Success, i.Fail, i.ErrorRate,
+		ms(i.ServiceTime.P50), ms(i.ServiceTime.P95), ms(i.ServiceTime.P99),
+		ms(i.ResponseTime.P50), ms(i.ResponseTime.P95), ms(i.ResponseTime.P99))
+}
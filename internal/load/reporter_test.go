@@ -0,0 +1,53 @@
+package load
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWindowsKeepEveryValue(t *testing.T) {
+	w := newWindows(1)
+	const recorders, values = 8, 5000
+	var wg sync.WaitGroup
+	for range recorders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range values {
+				if i%10 == 0 {
+					w.recordFailure()
+				} else {
+					w.record(time.Millisecond, true)
+				}
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	var ok, ko, recorded uint64
+	take := func() {
+		taken := w.take()
+		ok += taken.ok.Load()
+		ko += taken.ko.Load()
+		recorded += taken.latencies.Count()
+	}
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+			take()
+		}
+	}
+	take()
+	if want := uint64(recorders * values * 9 / 10); ok != want || recorded != want {
+		t.Errorf("ok = %d, recorded = %d, want %d", ok, recorded, want)
+	}
+	if want := uint64(recorders * values / 10); ko != want {
+		t.Errorf("ko = %d, want %d", ko, want)
+	}
+}
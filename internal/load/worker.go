@@ -0,0 +1,24 @@
+package load
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrExhausted is returned by Execute when the data the load sends is exhausted. The run stops
+// scheduling new requests and lets the requests in flight complete, it is not a failure
+var ErrExhausted = errors.New("data exhausted")
+
+type workerKey struct{}
+
+// withWorker tags the context of the requests of a worker with its index
+func withWorker(ctx context.Context, worker int) context.Context {
+	return context.WithValue(ctx, workerKey{}, worker)
+}
+
+// Worker returns the index of the worker or the virtual user executing a request, from 0 to
+// cfg.Concurrency-1, false when the request is not executed by a runner
+func Worker(ctx context.Context) (int, bool) {
+	worker, ok := ctx.Value(workerKey{}).(int)
+	return worker, ok
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 
 	"github.com/rk1165/loadsimulator/internal/load"
+	"github.com/rk1165/loadsimulator/internal/secret"
 )
 
 var (
@@ -21,7 +22,7 @@ func init() {
 		log.Fatalf("failed to open log file: %v", err)
 	}
 
-	multiWriter := io.MultiWriter(os.Stdout, logFile)
+	multiWriter := secret.Writer(io.MultiWriter(os.Stdout, logFile))
 
 	InfoLog = log.New(multiWriter, "   INFO: ", log.Ldate|log.Ltime)
 	WarnLog = log.New(multiWriter, "WARNING: ", log.Ldate|log.Ltime|log.Lshortfile)
@@ -37,8 +38,10 @@ func CreateLoadLog(file string) load.Log {
 	if err != nil {
 		log.Fatalf("failed to create log file %s error: %v", file, err)
 	}
-	writer := io.Writer(logFile)
-	InfoLog = log.New(writer, "   INFO: ", log.Ldate|log.Ltime|log.Lshortfile)
-	ErrorLog = log.New(writer, "  ERROR: ", log.Ldate|log.Ltime|log.Lshortfile)
-	return load.Log{InfoLog: InfoLog, ErrorLog: ErrorLog}
+	writer := secret.Writer(logFile)
+	// the package loggers are kept on the console and app.log for the main load run log
+	return load.Log{
+		InfoLog:  log.New(writer, "   INFO: ", log.Ldate|log.Ltime|log.Lshortfile),
+		ErrorLog: log.New(writer, "  ERROR: ", log.Ldate|log.Ltime|log.Lshortfile),
+	}
 }
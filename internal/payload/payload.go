@@ -0,0 +1,165 @@
+package payload
+
+import (
+	"fmt"
+	"io/fs"
+	"math/rand/v2"
+	"path"
+	"strings"
+
+	"github.com/rk1165/loadsimulator/internal/assets"
+	"github.com/rk1165/loadsimulator/internal/load"
+	"github.com/rk1165/loadsimulator/internal/types"
+)
+
+// Pool holds the payload variants of a scenario, a variant is picked for every request
+// following the weights. T is the payload as the load sends it, e.g. a parsed template
+type Pool[T any] struct {
+	variants []*Variant[T]
+	total    int
+}
+
+// Variant is a group of payloads, one of which is picked at random when the variant is
+type Variant[T any] struct {
+	Name     string
+	Stats    *load.PartStats // responses to the requests which sent the variant, without the execute errors like the load
+	payloads []T
+	weight   int
+}
+
+// New reads the payloads of a scenario. Without payloads the pool holds a single variant with
+// the content of fileName, empty when it is not set either. parse turns the content of a file
+// into the payload the load sends
+func New[T any](base types.BaseConfig, cfg types.Config, parse func(file, content string) (T, error)) (*Pool[T], error) {
+	p := &Pool[T]{}
+	if len(base.Payloads) == 0 {
+		payload, err := parse(base.FileName, base.ResolveBody())
+		if err != nil {
+			return nil, err
+		}
+		p.add(base.FileName, 1, []T{payload}, cfg)
+		return p, nil
+	}
+	names := make(map[string]bool)
+	for _, v := range base.Payloads {
+		name := v.DisplayName()
+		if names[name] {
+			return nil, fmt.Errorf("payload %s is not unique", name)
+		}
+		names[name] = true
+		weight := v.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		if weight < 0 {
+			return nil, fmt.Errorf("payload %s: invalid weight %d", name, v.Weight)
+		}
+		files, err := resolve(v.File)
+		if err != nil {
+			return nil, fmt.Errorf("payload %s: %v", name, err)
+		}
+		var payloads []T
+		for _, file := range files {
+			content, err := assets.Data.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("payload %s: %v", name, err)
+			}
+			payload, err := parse(file, string(content))
+			if err != nil {
+				return nil, fmt.Errorf("payload %s: %v", file, err)
+			}
+			payloads = append(payloads, payload)
+		}
+		p.add(name, weight, payloads, cfg)
+	}
+	return p, nil
+}
+
+func (p *Pool[T]) add(name string, weight int, payloads []T, cfg types.Config) {
+	p.variants = append(p.variants, &Variant[T]{Name: name, Stats: load.NewPartStats(cfg), payloads: payloads, weight: weight})
+	p.total += weight
+}
+
+// resolve returns the files of a file, a directory or a glob
+func resolve(file string) ([]string, error) {
+	if strings.ContainsAny(file, "*?[") {
+		files, err := fs.Glob(assets.Data, file)
+		if err != nil {
+			return nil, err
+		}
+		var regular []string
+		for _, f := range files {
+			if info, err := fs.Stat(assets.Data, f); err == nil && !info.IsDir() {
+				regular = append(regular, f)
+			}
+		}
+		if len(regular) == 0 {
+			return nil, fmt.Errorf("no file matches %s", file)
+		}
+		return regular, nil
+	}
+	info, err := fs.Stat(assets.Data, file)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{file}, nil
+	}
+	entries, err := fs.ReadDir(assets.Data, file)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			files = append(files, path.Join(file, e.Name()))
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no file in %s", file)
+	}
+	return files, nil
+}
+
+// Pick picks the variant of a request and one of its payloads
+func (p *Pool[T]) Pick() (*Variant[T], T) {
+	v := p.variants[0]
+	if len(p.variants) > 1 {
+		n := rand.IntN(p.total)
+		for _, v = range p.variants {
+			if n < v.weight {
+				break
+			}
+			n -= v.weight
+		}
+	}
+	if len(v.payloads) == 1 {
+		return v, v.payloads[0]
+	}
+	return v, v.payloads[rand.IntN(len(v.payloads))]
+}
+
+// Stats returns the stats of every variant, none when the pool has a single one
+func (p *Pool[T]) Stats() []load.StepStats {
+	if len(p.variants) < 2 {
+		return nil
+	}
+	stats := make([]load.StepStats, 0, len(p.variants))
+	for _, v := range p.variants {
+		stats = append(stats, load.StepStats{Name: v.Name, Stats: v.Stats.CalculateStats()})
+	}
+	return stats
+}
+
+func (p *Pool[T]) String() string {
+	variants := make([]string, 0, len(p.variants))
+	for _, v := range p.variants {
+		variants = append(variants, fmt.Sprintf("%s=%d/%d(%d files)", v.Name, v.weight, p.total, len(v.payloads)))
+	}
+	return strings.Join(variants, " ")
+}
+
+// Text is the parse function of the loads sending the content of the files as it is
+func Text(file, content string) (string, error) {
+	return content, nil
+}
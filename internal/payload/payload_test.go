@@ -0,0 +1,189 @@
+package payload
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rk1165/loadsimulator/internal/assets"
+	"github.com/rk1165/loadsimulator/internal/types"
+)
+
+// dataDir writes files, by path relative to a temporary data dir, and reads the data files from there
+func dataDir(t *testing.T, files map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assets.SetDataDir(dir)
+	t.Cleanup(func() { assets.SetDataDir("") })
+}
+
+// large is a payload of n items, too large to be kept with the examples
+func large(n int) string {
+	items := make([]string, n)
+	for i := range items {
+		items[i] = `{"sku": "SKU-` + strings.Repeat("9", 4) + `", "quantity": 1}`
+	}
+	return `{"orderNumber": "ORD-{{sequence}}", "items": [` + strings.Join(items, ", ") + `]}`
+}
+
+func orders(t *testing.T) {
+	dataDir(t, map[string]string{
+		"orders/small/book.json":       "book",
+		"orders/small/pen.json":        "pen",
+		"orders/medium.json":           "medium",
+		"orders/large1.json":           large(5000),
+		"orders/large2.json":           large(10000),
+		"orders/large3.json/ignored":   "a directory matching the glob",
+		"orders/empty/sub/nested.json": "only in a sub directory",
+	})
+}
+
+func TestNew(t *testing.T) {
+	orders(t)
+	pool, err := New(types.BaseConfig{Payloads: []types.Payload{
+		{Name: "small", File: "orders/small", Weight: 70},
+		{File: "orders/medium.json", Weight: 25},
+		{Name: "large", File: "orders/large*.json"},
+	}}, types.Config{}, Text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		weight   int
+		payloads []string
+	}{
+		{"small", 70, []string{"book", "pen"}},
+		{"orders/medium.json", 25, []string{"medium"}},
+		{"large", 1, []string{large(5000), large(10000)}},
+	}
+	if len(pool.variants) != len(tests) || pool.total != 96 {
+		t.Fatalf("New() = %s, want %d variants weighing 96", pool, len(tests))
+	}
+	for i, tt := range tests {
+		v := pool.variants[i]
+		slices.Sort(v.payloads)
+		slices.Sort(tt.payloads)
+		if v.Name != tt.name || v.weight != tt.weight || !slices.Equal(v.payloads, tt.payloads) {
+			t.Errorf("variant %d = %s weight %d %d payloads, want %s weight %d %d payloads",
+				i, v.Name, v.weight, len(v.payloads), tt.name, tt.weight, len(tt.payloads))
+		}
+	}
+}
+
+func TestNewFileName(t *testing.T) {
+	orders(t)
+	pool, err := New(types.BaseConfig{FileName: "orders/medium.json"}, types.Config{}, Text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, payload := pool.Pick(); v.Name != "orders/medium.json" || payload != "medium" {
+		t.Errorf("Pick() = %s %q, want the content of fileName", v.Name, payload)
+	}
+	if stats := pool.Stats(); stats != nil {
+		t.Errorf("Stats() = %v, want none for a single variant", stats)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	orders(t)
+	tests := []struct {
+		name     string
+		payloads []types.Payload
+	}{
+		{"same name", []types.Payload{{Name: "a", File: "orders/medium.json"}, {Name: "a", File: "orders/small"}}},
+		{"same file", []types.Payload{{File: "orders/medium.json"}, {File: "orders/medium.json"}}},
+		{"negative weight", []types.Payload{{File: "orders/medium.json", Weight: -1}}},
+		{"missing file", []types.Payload{{File: "orders/missing.json"}}},
+		{"no match", []types.Payload{{File: "orders/huge*.json"}}},
+		{"invalid glob", []types.Payload{{File: "orders/[.json"}}},
+		{"no file in the directory", []types.Payload{{File: "orders/empty"}}},
+	}
+	for _, tt := range tests {
+		if _, err := New(types.BaseConfig{Payloads: tt.payloads}, types.Config{}, Text); err == nil {
+			t.Errorf("New() %s = nil error, want one", tt.name)
+		}
+	}
+}
+
+func TestPick(t *testing.T) {
+	orders(t)
+	pool, err := New(types.BaseConfig{Payloads: []types.Payload{
+		{Name: "small", File: "orders/small", Weight: 6},
+		{Name: "medium", File: "orders/medium.json", Weight: 3},
+		{Name: "large", File: "orders/large*.json"},
+	}}, types.Config{}, Text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const picks = 20000
+	variants := make(map[string]int)
+	payloads := make(map[string]int)
+	for range picks {
+		v, payload := pool.Pick()
+		variants[v.Name]++
+		payloads[payload]++
+	}
+	for name, weight := range map[string]int{"small": 6, "medium": 3, "large": 1} {
+		want := float64(weight) / 10
+		if got := float64(variants[name]) / picks; math.Abs(got-want) > 0.02 {
+			t.Errorf("variant %s picked %.3f of the time, want %.3f", name, got, want)
+		}
+	}
+	// the files of a variant are picked evenly
+	if book, pen := payloads["book"], payloads["pen"]; math.Abs(float64(book-pen)) > 0.1*float64(book+pen) {
+		t.Errorf("book picked %d times and pen %d times, want as often", book, pen)
+	}
+}
+
+func TestStats(t *testing.T) {
+	orders(t)
+	pool, err := New(types.BaseConfig{Payloads: []types.Payload{
+		{Name: "small", File: "orders/small", Weight: 3},
+		{Name: "medium", File: "orders/medium.json"},
+	}}, types.Config{}, Text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ok, ko uint64
+	for i := range 1000 {
+		v, _ := pool.Pick()
+		if i%10 == 0 {
+			v.Stats.RecordFailure(time.Millisecond, "http 503", "service unavailable")
+			ko++
+		} else {
+			v.Stats.Record(time.Millisecond, true)
+			ok++
+		}
+	}
+	stats := pool.Stats()
+	if len(stats) != 2 || stats[0].Name != "small" || stats[1].Name != "medium" {
+		t.Fatalf("Stats() = %v, want small and medium", stats)
+	}
+	var total, success, fail, failures uint64
+	for _, s := range stats {
+		total += s.Stats.Total
+		success += s.Stats.Success
+		fail += s.Stats.Fail
+		failures += s.Stats.Failures["http 503"].Count
+		if s.Stats.Total == 0 || s.Stats.ServiceTime.P50 != time.Millisecond {
+			t.Errorf("variant %s stats = %v", s.Name, s.Stats)
+		}
+	}
+	if total != ok+ko || success != ok || fail != ko || failures != ko {
+		t.Errorf("variants add up to total=%d success=%d fail=%d, want %d %d %d", total, success, fail, ok+ko, ok, ko)
+	}
+}
@@ -0,0 +1,170 @@
+package plan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	awsConfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/rk1165/loadsimulator/internal/aws"
+	"github.com/rk1165/loadsimulator/internal/config"
+	"github.com/rk1165/loadsimulator/internal/kafka"
+	"github.com/rk1165/loadsimulator/internal/load"
+	"github.com/rk1165/loadsimulator/internal/logger"
+	"github.com/rk1165/loadsimulator/internal/report"
+	"github.com/rk1165/loadsimulator/internal/rest"
+	"github.com/rk1165/loadsimulator/internal/types"
+)
+
+// Scenario is a scenario of a plan ready to run
+type Scenario struct {
+	types.PlanScenario
+	Config any // the scenario as loaded from its config file
+	Runner *load.Runner
+}
+
+// NewScenario loads the config of a scenario and builds the load and the runner of its config type
+func NewScenario(ctx context.Context, s types.PlanScenario) (*Scenario, error) {
+	configFile := s.ConfigFile()
+	scenario := &Scenario{PlanScenario: s}
+	switch s.ConfigType {
+	case "rest":
+		apiConfig, cfg, err := loadTestConfig[types.ApiConfig](configFile, s)
+		if err != nil {
+			return nil, err
+		}
+		scenario.Config = apiConfig
+		api, err := rest.NewApi(apiConfig, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("scenario %s: %v", s.DisplayName(), err)
+		}
+		scenario.Runner = load.NewLoadRunner(api, cfg)
+	case "journey":
+		journeyConfig, cfg, err := loadTestConfig[types.JourneyConfig](configFile, s)
+		if err != nil {
+			return nil, err
+		}
+		scenario.Config = journeyConfig
+		journey, err := rest.NewJourney(journeyConfig, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("scenario %s: %v", s.DisplayName(), err)
+		}
+		scenario.Runner = load.NewLoadRunner(journey, cfg)
+	case "aws":
+		switch s.SubConfig {
+		case "s3":
+			s3Config, cfg, err := loadTestConfig[types.S3Config](configFile, s)
+			if err != nil {
+				return nil, err
+			}
+			awsS3Config, err := awsConfig.LoadDefaultConfig(ctx, awsConfig.WithRegion(s3Config.Region))
+			if err != nil {
+				return nil, err
+			}
+			scenario.Config = s3Config
+			s3Client := s3.NewFromConfig(awsS3Config)
+			s3Load, err := aws.NewS3(s3Config, cfg, s3Client)
+			if err != nil {
+				return nil, fmt.Errorf("scenario %s: %v", s.DisplayName(), err)
+			}
+			scenario.Runner = load.NewLoadRunner(s3Load, cfg)
+		case "sqs":
+			sqsConfig, cfg, err := loadTestConfig[types.SqsConfig](configFile, s)
+			if err != nil {
+				return nil, err
+			}
+			awsSqsConfig, err := awsConfig.LoadDefaultConfig(ctx, awsConfig.WithRegion(sqsConfig.Region))
+			if err != nil {
+				return nil, err
+			}
+			scenario.Config = sqsConfig
+			sqsClient := sqs.NewFromConfig(awsSqsConfig)
+			sqsLoad, err := aws.NewSqs(sqsConfig, cfg, sqsClient)
+			if err != nil {
+				return nil, fmt.Errorf("scenario %s: %v", s.DisplayName(), err)
+			}
+			scenario.Runner = load.NewLoadRunner(sqsLoad, cfg)
+		default:
+			return nil, fmt.Errorf("unknown subConfig %s for configType aws", s.SubConfig)
+		}
+	case "kafka":
+		kafkaConfig, cfg, err := loadTestConfig[types.KafkaConfig](configFile, s)
+		if err != nil {
+			return nil, err
+		}
+		scenario.Config = kafkaConfig
+		kafkaLoad, err := kafka.NewKafka(kafkaConfig, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("scenario %s: %v", s.DisplayName(), err)
+		}
+		logger.InfoLog.Printf("Kafka Loader Initialized")
+		scenario.Runner = load.NewLoadRunner(kafkaLoad, cfg)
+	default:
+		return nil, fmt.Errorf("unknown configType: %s", s.ConfigType)
+	}
+	return scenario, nil
+}
+
+// loadTestConfig loads the config of the scenario, named after the scenario of the plan
+func loadTestConfig[T types.Provider](configFile string, s types.PlanScenario) (T, types.Config, error) {
+	scenario, cfg, err := config.LoadTestConfig[T](configFile, s.Scenario)
+	cfg.Name = s.DisplayName()
+	return scenario, cfg, err
+}
+
+// Run runs the scenarios at the same time, each one starting StartAfter after the start of the
+// plan, and returns the report of the scenarios which ran along with the errors of their runs
+// Cancelling ctx interrupts the running scenarios and the scenarios which did not start yet never do
+func Run(ctx context.Context, scenarios []*Scenario) (*report.Report, error) {
+	rep := report.New()
+	results := make([]*load.Stats, len(scenarios))
+	errs := make([]error, len(scenarios))
+	var wg sync.WaitGroup
+	for i, s := range scenarios {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if s.StartAfter > 0 {
+				logger.InfoLog.Printf("scenario=%s starts in %s", s.DisplayName(), s.StartAfter)
+				if err := wait(ctx, s.StartAfter); err != nil {
+					logger.ErrorLog.Printf("scenario=%s never started: %v", s.DisplayName(), err)
+					return
+				}
+			}
+			results[i], errs[i] = run(ctx, s)
+		}()
+	}
+	wg.Wait()
+	for i, s := range scenarios {
+		if results[i] != nil {
+			rep.Add(s.DisplayName(), s.ConfigType, s.SubConfig, s.Config, s.Runner, results[i])
+		}
+	}
+	return rep, errors.Join(errs...)
+}
+
+// run runs a scenario and returns its stats, nil when the run did not happen
+func run(ctx context.Context, s *Scenario) (*load.Stats, error) {
+	ch := make(chan *load.Stats, 1)
+	err := s.Runner.Run(ctx, ch)
+	stats, ok := <-ch
+	if err != nil {
+		err = fmt.Errorf("load run failed for load=%s/%s scenario=%s with error=[%v]",
+			s.ConfigType, s.SubConfig, s.DisplayName(), err)
+	}
+	if !ok {
+		return nil, err
+	}
+	switch {
+	case stats.Interrupted:
+		logger.ErrorLog.Printf("Load interrupted for scenario: %s, partial stats: %v", s.DisplayName(), stats)
+	case err != nil:
+		logger.ErrorLog.Printf("Load finished with errors for scenario: %s, stats: %v", s.DisplayName(), stats)
+	default:
+		logger.InfoLog.Printf("Load finished successfully for scenario: %s, stats: %v", s.DisplayName(), stats)
+	}
+	return stats, err
+}
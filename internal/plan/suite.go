@@ -0,0 +1,126 @@
+package plan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rk1165/loadsimulator/internal/aws"
+	"github.com/rk1165/loadsimulator/internal/logger"
+	"github.com/rk1165/loadsimulator/internal/report"
+	"github.com/rk1165/loadsimulator/internal/types"
+)
+
+// Step is a setup or teardown step of a suite ready to run
+type Step struct {
+	types.PlanStep
+	scenario *Scenario
+}
+
+// NewStep builds the load of the scenario of a step
+func NewStep(ctx context.Context, step types.PlanStep) (*Step, error) {
+	scenario, err := NewScenario(ctx, step.PlanScenario)
+	if err != nil {
+		return nil, err
+	}
+	if step.Action == types.StepS3Cleanup {
+		if _, ok := scenario.Runner.Load.(*aws.LoadS3); !ok {
+			return nil, fmt.Errorf("step %s: %s needs an s3 scenario", step.DisplayName(), step.Action)
+		}
+	}
+	return &Step{PlanStep: step, scenario: scenario}, nil
+}
+
+// Run runs the action of the step once
+func (s *Step) Run(ctx context.Context) error {
+	if s.Action == types.StepS3Cleanup {
+		_, err := s.scenario.Runner.Load.(*aws.LoadS3).Cleanup(ctx)
+		return err
+	}
+	l := s.scenario.Runner.Load
+	err := l.Execute(ctx, 1)
+	stats := l.CalculateStats()
+	if err != nil {
+		return err
+	}
+	if stats.Fail > 0 {
+		return fmt.Errorf("unexpected response, stats: %v", stats)
+	}
+	return nil
+}
+
+// RunSuite runs the setup steps, then the scenarios one after the other, each one starting
+// StartAfter after the end of the previous one, and the teardown steps. It returns a report
+// per phase which ran, in the order of the phases, along with the errors of the steps and runs
+// A failed setup step skips the scenarios, the teardown steps run even when the scenarios
+// failed or ctx was cancelled
+func RunSuite(ctx context.Context, setup []*Step, scenarios []*Scenario, teardown []*Step) ([]*report.Report, error) {
+	var errs []error
+	var reports []*report.Report
+	if err := runSteps(ctx, "setup", setup); err != nil {
+		errs = append(errs, err)
+		logger.ErrorLog.Printf("[SUITE] skipping the scenarios after the failed setup")
+		scenarios = nil
+	}
+	for _, s := range scenarios {
+		if err := wait(ctx, s.StartAfter); err != nil {
+			logger.ErrorLog.Printf("[SUITE] scenario=%s never started: %v", s.DisplayName(), err)
+			break
+		}
+		logger.InfoLog.Printf("[SUITE] phase=%s scenario=%s", s.PhaseName(), s.DisplayName())
+		stats, err := run(ctx, s)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if stats == nil {
+			continue
+		}
+		if len(reports) == 0 || reports[len(reports)-1].Phase != s.PhaseName() {
+			rep := report.New()
+			rep.Phase = s.PhaseName()
+			reports = append(reports, rep)
+		}
+		reports[len(reports)-1].Add(s.DisplayName(), s.ConfigType, s.SubConfig, s.Config, s.Runner, stats)
+	}
+	// the teardown cleans up after an interrupted suite too
+	if err := runSteps(context.WithoutCancel(ctx), "teardown", teardown); err != nil {
+		errs = append(errs, err)
+	}
+	return reports, errors.Join(errs...)
+}
+
+// runSteps runs the steps in order, stopping at the first failed one in the setup and running all
+// of them in the teardown
+func runSteps(ctx context.Context, kind string, steps []*Step) error {
+	var errs []error
+	for _, step := range steps {
+		if err := wait(ctx, step.StartAfter); err != nil {
+			return err
+		}
+		start := time.Now()
+		if err := step.Run(ctx); err != nil {
+			err = fmt.Errorf("%s step %s failed: %w", kind, step.DisplayName(), err)
+			logger.ErrorLog.Printf("[SUITE] %v", err)
+			if kind == "setup" {
+				return err
+			}
+			errs = append(errs, err)
+			continue
+		}
+		logger.InfoLog.Printf("[SUITE] %s step %s done in %s", kind, step.DisplayName(), time.Since(start))
+	}
+	return errors.Join(errs...)
+}
+
+func wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,91 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var csvHeader = []string{
+	"scenario", "configType", "subConfig", "startTime", "durationSeconds",
+	"scheduled", "started", "completed", "failed", "cancelled", "throughput",
+	"total", "success", "fail", "errorRate",
+	"minMs", "avgMs", "p50Ms", "p90Ms", "p95Ms", "p99Ms", "maxMs",
+	"responseMinMs", "responseAvgMs", "responseP50Ms", "responseP90Ms", "responseP95Ms", "responseP99Ms", "responseMaxMs",
+	"thresholds", "thresholdsFailed", "aborted", "interrupted", "exhausted", "errorCategories", "assertionsFailed",
+}
+
+// csv writes one row per scenario
+func (r *Report) csv() ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(csvHeader); err != nil {
+		return nil, err
+	}
+	for _, s := range r.Scenarios {
+		row := []string{
+			s.Name, s.ConfigType, s.SubConfig, s.StartTime.Format(time.RFC3339), formatFloat(s.DurationS),
+			formatUint(s.Counters.Scheduled), formatUint(s.Counters.Started), formatUint(s.Counters.Completed),
+			formatUint(s.Counters.Failed), formatUint(s.Counters.Cancelled), formatFloat(s.Counters.Throughput),
+			formatUint(s.Stats.Total), formatUint(s.Stats.Success), formatUint(s.Stats.Fail), formatFloat(s.Stats.ErrorRate),
+		}
+		row = append(row, s.Stats.ServiceTime.columns()...)
+		row = append(row, s.Stats.ResponseTime.columns()...)
+		failed := 0
+		for _, result := range s.Thresholds {
+			if !result.Passed {
+				failed++
+			}
+		}
+		row = append(row, strconv.Itoa(len(s.Thresholds)), strconv.Itoa(failed), s.Aborted,
+			strconv.FormatBool(s.Interrupted), strconv.FormatBool(s.Exhausted), s.Errors.categories(), assertionsFailed(s.Assertions))
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func (l Latency) columns() []string {
+	return []string{
+		formatFloat(l.Min), formatFloat(l.Avg), formatFloat(l.P50), formatFloat(l.P90),
+		formatFloat(l.P95), formatFloat(l.P99), formatFloat(l.Max),
+	}
+}
+
+// categories formats the error categories as `category=count` pairs sorted by category
+func (e Errors) categories() string {
+	keys := make([]string, 0, len(e.Categories))
+	for category := range e.Categories {
+		keys = append(keys, category)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, category := range keys {
+		pairs = append(pairs, category+"="+formatUint(e.Categories[category].Count))
+	}
+	return strings.Join(pairs, " ")
+}
+
+// assertionsFailed formats the failed assertions as `name=failed` pairs, the names may hold spaces
+func assertionsFailed(assertions []Assertion) string {
+	var pairs []string
+	for _, a := range assertions {
+		if a.Failed > 0 {
+			pairs = append(pairs, a.Name+"="+formatUint(a.Failed))
+		}
+	}
+	return strings.Join(pairs, "; ")
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 3, 64)
+}
+
+func formatUint(u uint64) string {
+	return strconv.FormatUint(u, 10)
+}
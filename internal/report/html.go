@@ -0,0 +1,253 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	chartWidth  = 860
+	chartHeight = 260
+	chartMargin = 48
+)
+
+// series is a named line of a chart
+type series struct {
+	Name   string
+	Color  string
+	Points [][2]float64 // x, y
+}
+
+// html renders a single static page with inline svg charts which needs nothing but a browser
+func (r *Report) html() ([]byte, error) {
+	t, err := template.New("report").Funcs(template.FuncMap{
+		"latencyChart":    latencyChart,
+		"throughputChart": throughputChart,
+		"histogramChart":  histogramChart,
+		"ms":              func(f float64) string { return fmt.Sprintf("%.3f", f) },
+		"pct":             func(f float64) string { return fmt.Sprintf("%.2f%%", f) },
+		"rfc3339":         func(t time.Time) string { return t.Format(time.RFC3339) },
+	}).Parse(htmlTemplate)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func latencyChart(timeline []Interval) template.HTML {
+	pick := func(f func(Interval) float64) [][2]float64 {
+		points := make([][2]float64, 0, len(timeline))
+		for _, i := range timeline {
+			points = append(points, [2]float64{i.ElapsedS, f(i)})
+		}
+		return points
+	}
+	return lineChart("elapsed (s)", "latency (ms)", []series{
+		{Name: "p50", Color: "#4c78a8", Points: pick(func(i Interval) float64 { return i.ServiceTime.P50 })},
+		{Name: "p95", Color: "#f58518", Points: pick(func(i Interval) float64 { return i.ServiceTime.P95 })},
+		{Name: "p99", Color: "#e45756", Points: pick(func(i Interval) float64 { return i.ServiceTime.P99 })},
+		{Name: "response p99", Color: "#b279a2", Points: pick(func(i Interval) float64 { return i.ResponseTime.P99 })},
+	})
+}
+
+func throughputChart(timeline []Interval) template.HTML {
+	var ok, ko [][2]float64
+	for _, i := range timeline {
+		total := float64(i.Success + i.Fail)
+		okRate := i.Throughput
+		if total > 0 {
+			okRate = i.Throughput * float64(i.Success) / total
+		}
+		ok = append(ok, [2]float64{i.ElapsedS, okRate})
+		ko = append(ko, [2]float64{i.ElapsedS, i.Throughput - okRate})
+	}
+	return lineChart("elapsed (s)", "requests/s", []series{
+		{Name: "success", Color: "#54a24b", Points: ok},
+		{Name: "fail", Color: "#e45756", Points: ko},
+	})
+}
+
+// lineChart draws the series against shared linear axes
+func lineChart(xLabel, yLabel string, lines []series) template.HTML {
+	var maxX, maxY float64
+	for _, l := range lines {
+		for _, p := range l.Points {
+			maxX = math.Max(maxX, p[0])
+			maxY = math.Max(maxY, p[1])
+		}
+	}
+	if maxX == 0 || maxY == 0 {
+		return template.HTML(`<p class="empty">no data</p>`)
+	}
+	maxY = niceCeil(maxY)
+	plotW := float64(chartWidth - 2*chartMargin)
+	plotH := float64(chartHeight - 2*chartMargin)
+	x := func(v float64) float64 { return chartMargin + v/maxX*plotW }
+	y := func(v float64) float64 { return chartHeight - chartMargin - v/maxY*plotH }
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg viewBox="0 0 %d %d" xmlns="http://www.w3.org/2000/svg">`, chartWidth, chartHeight)
+	axes(&b, xLabel, yLabel, maxX, maxY, x, y)
+	for i, l := range lines {
+		var points []string
+		for _, p := range l.Points {
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x(p[0]), y(p[1])))
+		}
+		fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="1.5" points="%s"/>`,
+			l.Color, strings.Join(points, " "))
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="10" height="10" fill="%s"/><text x="%d" y="%d">%s</text>`,
+			chartMargin+10+i*120, 12, l.Color, chartMargin+24+i*120, 21, template.HTMLEscapeString(l.Name))
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// histogramChart draws a bar per bucket, buckets being of uneven widths they are drawn side by side
+func histogramChart(buckets []Bucket) template.HTML {
+	if len(buckets) == 0 {
+		return template.HTML(`<p class="empty">no data</p>`)
+	}
+	var maxCount uint64
+	for _, bucket := range buckets {
+		maxCount = max(maxCount, bucket.Count)
+	}
+	plotW := float64(chartWidth - 2*chartMargin)
+	plotH := float64(chartHeight - 2*chartMargin)
+	barW := plotW / float64(len(buckets))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg viewBox="0 0 %d %d" xmlns="http://www.w3.org/2000/svg">`, chartWidth, chartHeight)
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#888"/>`,
+		chartMargin, chartHeight-chartMargin, chartWidth-chartMargin, chartHeight-chartMargin)
+	for i, bucket := range buckets {
+		h := float64(bucket.Count) / float64(maxCount) * plotH
+		left := chartMargin + float64(i)*barW
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="#4c78a8"><title>&le; %gms: %d</title></rect>`,
+			left+1, chartHeight-chartMargin-h, barW-2, h, bucket.UpToMs, bucket.Count)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="middle">%g</text>`,
+			left+barW/2, chartHeight-chartMargin+16, bucket.UpToMs)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="middle">%d</text>`,
+			left+barW/2, chartHeight-chartMargin-h-4, bucket.Count)
+	}
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle">service time upper bound (ms)</text>`,
+		chartWidth/2, chartHeight-8)
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+func axes(b *strings.Builder, xLabel, yLabel string, maxX, maxY float64, x, y func(float64) float64) {
+	fmt.Fprintf(b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#888"/>`, x(0), y(0), x(maxX), y(0))
+	fmt.Fprintf(b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#888"/>`, x(0), y(0), x(0), y(maxY))
+	for i := 0; i <= 4; i++ {
+		vy := maxY * float64(i) / 4
+		fmt.Fprintf(b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#eee"/>`, x(0), y(vy), x(maxX), y(vy))
+		fmt.Fprintf(b, `<text x="%.1f" y="%.1f" text-anchor="end">%.4g</text>`, x(0)-4, y(vy)+4, vy)
+		vx := maxX * float64(i) / 4
+		fmt.Fprintf(b, `<text x="%.1f" y="%.1f" text-anchor="middle">%.4g</text>`, x(vx), y(0)+16, vx)
+	}
+	fmt.Fprintf(b, `<text x="%d" y="%d" text-anchor="middle">%s</text>`,
+		chartWidth/2, chartHeight-8, template.HTMLEscapeString(xLabel))
+	fmt.Fprintf(b, `<text x="12" y="%d" transform="rotate(-90 12 %d)" text-anchor="middle">%s</text>`,
+		chartHeight/2, chartHeight/2, template.HTMLEscapeString(yLabel))
+}
+
+// niceCeil rounds v up to 1, 2 or 5 times a power of ten so that axis ticks are readable
+func niceCeil(v float64) float64 {
+	magnitude := math.Pow10(int(math.Floor(math.Log10(v))))
+	for _, step := range []float64{1, 2, 5, 10} {
+		if v <= step*magnitude {
+			return step * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Load report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 960px; color: #222; }
+h1 { font-size: 1.6em; } h2 { border-bottom: 1px solid #ddd; padding-bottom: .2em; } h3 { font-size: 1.05em; color: #555; }
+table { border-collapse: collapse; margin: .5em 0 1em; }
+th, td { border: 1px solid #ddd; padding: .3em .7em; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+svg { width: 100%; height: auto; font-size: 11px; fill: #444; }
+.empty { color: #999; } .fail { color: #c0392b; } pre { background: #f6f6f6; padding: .8em; overflow-x: auto; }
+</style>
+</head>
+<body>
+<h1>Load report{{if .Phase}} - {{.Phase}} phase{{end}}</h1>
+<p>Generated at {{rfc3339 .GeneratedAt}}</p>
+{{range .Scenarios}}
+<h2>{{.Name}} <small>({{.ConfigType}}/{{.SubConfig}})</small></h2>
+{{if .Aborted}}<p class="fail">Aborted: {{.Aborted}}</p>{{end}}
+{{if .Interrupted}}<p class="fail">Interrupted: the results are those of the requests which ran before the run was stopped</p>{{end}}
+{{if .Exhausted}}<p>Exhausted: the run stopped once the rows of its feeder were all used</p>{{end}}
+<p>Started at {{rfc3339 .StartTime}}, ran for {{printf "%.1f" .DurationS}}s at {{printf "%.1f" .Counters.Throughput}} requests/s</p>
+<table>
+<tr><th>scheduled</th><th>started</th><th>completed</th><th>total</th><th>success</th><th>fail</th><th>execute errors</th><th>error rate</th></tr>
+<tr><td>{{.Counters.Scheduled}}</td><td>{{.Counters.Started}}</td><td>{{.Counters.Completed}}</td><td>{{.Stats.Total}}</td>
+<td>{{.Stats.Success}}</td><td{{if .Stats.Fail}} class="fail"{{end}}>{{.Stats.Fail}}</td>
+<td{{if .Counters.Failed}} class="fail"{{end}}>{{.Counters.Failed}}</td><td>{{pct .Stats.ErrorRate}}</td></tr>
+</table>
+{{if .Thresholds}}<h3>Thresholds</h3>
+<table>
+<tr><th>threshold</th><th>actual</th><th>expected</th><th>result</th></tr>
+{{range .Thresholds}}<tr><td>{{.Expression}}</td><td>{{ms .Actual}}</td><td>{{ms .Expected}}</td><td{{if not .Passed}} class="fail"{{end}}>{{if .Passed}}passed{{else}}failed{{end}}</td></tr>
+{{end}}</table>{{end}}
+<h3>Percentiles (ms)</h3>
+<table>
+<tr><th></th><th>min</th><th>avg</th><th>p50</th><th>p90</th><th>p95</th><th>p99</th><th>max</th></tr>
+{{with .Stats.ServiceTime}}<tr><td>service time</td><td>{{ms .Min}}</td><td>{{ms .Avg}}</td><td>{{ms .P50}}</td><td>{{ms .P90}}</td><td>{{ms .P95}}</td><td>{{ms .P99}}</td><td>{{ms .Max}}</td></tr>{{end}}
+{{with .Stats.ResponseTime}}<tr><td>response time</td><td>{{ms .Min}}</td><td>{{ms .Avg}}</td><td>{{ms .P50}}</td><td>{{ms .P90}}</td><td>{{ms .P95}}</td><td>{{ms .P99}}</td><td>{{ms .Max}}</td></tr>{{end}}
+</table>
+{{if .Steps}}<h3>Steps</h3>
+<table>
+<tr><th>step</th><th>total</th><th>success</th><th>fail</th><th>error rate</th><th>p50 ms</th><th>p95 ms</th><th>p99 ms</th><th>max ms</th></tr>
+{{range .Steps}}<tr><td>{{.Name}}</td><td>{{.Total}}</td><td>{{.Success}}</td><td{{if .Fail}} class="fail"{{end}}>{{.Fail}}</td><td>{{pct .ErrorRate}}</td>
+<td>{{ms .ServiceTime.P50}}</td><td>{{ms .ServiceTime.P95}}</td><td>{{ms .ServiceTime.P99}}</td><td>{{ms .ServiceTime.Max}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Variants}}<h3>Payload variants</h3>
+<table>
+<tr><th>variant</th><th>total</th><th>success</th><th>fail</th><th>error rate</th><th>p50 ms</th><th>p95 ms</th><th>p99 ms</th><th>max ms</th></tr>
+{{range .Variants}}<tr><td>{{.Name}}</td><td>{{.Total}}</td><td>{{.Success}}</td><td{{if .Fail}} class="fail"{{end}}>{{.Fail}}</td><td>{{pct .ErrorRate}}</td>
+<td>{{ms .ServiceTime.P50}}</td><td>{{ms .ServiceTime.P95}}</td><td>{{ms .ServiceTime.P99}}</td><td>{{ms .ServiceTime.Max}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Assertions}}<h3>Assertions</h3>
+<table>
+<tr><th>assertion</th><th>passed</th><th>failed</th></tr>
+{{range .Assertions}}<tr><td>{{.Name}}</td><td>{{.Passed}}</td><td{{if .Failed}} class="fail"{{end}}>{{.Failed}}</td></tr>
+{{end}}</table>{{end}}
+<h3>Latency over time</h3>
+{{latencyChart .Timeline}}
+<h3>Throughput over time</h3>
+{{throughputChart .Timeline}}
+<h3>Service time distribution</h3>
+{{histogramChart .Histogram}}
+<h3>Errors</h3>
+<table>
+<tr><th>kind</th><th>count</th></tr>
+<tr><td>failed responses</td><td>{{.Errors.FailedResponses}}</td></tr>
+<tr><td>execute errors</td><td>{{.Errors.ExecuteErrors}}</td></tr>
+<tr><td>cancelled</td><td>{{.Counters.Cancelled}}</td></tr>
+</table>
+{{if .Errors.Categories}}<table>
+<tr><th>category</th><th>count</th><th>sample</th></tr>
+{{range $category, $count := .Errors.Categories}}<tr><td>{{$category}}</td><td>{{$count.Count}}</td><td>{{$count.Sample}}</td></tr>
+{{end}}</table>{{end}}
+{{if .FailedByErrors}}<p class="fail">The run failed: the error policy is {{.Errors.Policy}} and requests returned an error</p>{{end}}
+{{if .Errors.FirstError}}<p>First error:</p><pre>{{.Errors.FirstError}}</pre>{{end}}
+{{end}}
+</body>
+</html>
+`
@@ -0,0 +1,144 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Time     float64      `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Time       float64         `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitCase     `xml:"testcase"`
+	SystemOut  junitOutput     `xml:"system-out"`
+}
+
+type junitOutput struct {
+	Text string `xml:",cdata"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junit writes a test suite per scenario with a test case per check the scenario is judged on:
+// a test case per threshold, or without thresholds a test case per assertion and a "requests"
+// test case which fails when any of its requests failed. An aborted scenario gets an extra failed
+// test case and with the fail error policy an "errors" test case fails when any request returned
+// an error. The process exits with status 1 when any of these test cases fails
+func (r *Report) junit() ([]byte, error) {
+	suites := junitSuites{Name: "loadsimulator"}
+	if r.Phase != "" {
+		suites.Name += "-" + r.Phase
+	}
+	for _, s := range r.Scenarios {
+		suite := junitSuite{
+			Name:      s.Name,
+			Time:      s.DurationS,
+			Timestamp: s.StartTime.Format("2006-01-02T15:04:05"),
+			Properties: []junitProperty{
+				{Name: "configType", Value: s.ConfigType},
+				{Name: "subConfig", Value: s.SubConfig},
+				{Name: "throughput", Value: formatFloat(s.Counters.Throughput)},
+				{Name: "targetRate", Value: formatFloat(s.Counters.TargetRate)},
+				{Name: "errorRate", Value: formatFloat(s.Stats.ErrorRate)},
+				{Name: "p95Ms", Value: formatFloat(s.Stats.ServiceTime.P95)},
+				{Name: "p99Ms", Value: formatFloat(s.Stats.ServiceTime.P99)},
+				{Name: "responseP99Ms", Value: formatFloat(s.Stats.ResponseTime.P99)},
+				{Name: "interrupted", Value: strconv.FormatBool(s.Interrupted)},
+			},
+			SystemOut: junitOutput{Text: s.summary()},
+		}
+		for _, c := range s.checks() {
+			testCase := junitCase{Name: c.name, ClassName: s.Name, Time: s.DurationS}
+			if c.message != "" {
+				testCase.Failure = &junitFailure{Message: c.message, Type: c.kind, Text: c.detail}
+			}
+			suite.Cases = append(suite.Cases, testCase)
+		}
+		for _, c := range suite.Cases {
+			suite.Tests++
+			if c.Failure != nil {
+				suite.Failures++
+			}
+		}
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Time += suite.Time
+		suites.Suites = append(suites.Suites, suite)
+	}
+	content, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), content...), nil
+}
+
+// summary is a human readable summary of the scenario
+func (s Scenario) summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "scheduled=%d started=%d completed=%d failed=%d cancelled=%d throughput=%.1f/s\n",
+		s.Counters.Scheduled, s.Counters.Started, s.Counters.Completed, s.Counters.Failed, s.Counters.Cancelled,
+		s.Counters.Throughput)
+	if s.Aborted != "" {
+		fmt.Fprintf(&b, "aborted: %s\n", s.Aborted)
+	}
+	if s.Interrupted {
+		b.WriteString("interrupted: partial results\n")
+	}
+	if s.Exhausted {
+		b.WriteString("exhausted: stopped once the rows of the feeder were all used\n")
+	}
+	fmt.Fprintf(&b, "total=%d success=%d fail=%d errorRate=%.2f%%\n",
+		s.Stats.Total, s.Stats.Success, s.Stats.Fail, s.Stats.ErrorRate)
+	if len(s.Errors.Categories) > 0 {
+		fmt.Fprintf(&b, "failures %s\n", s.Errors.categories())
+	}
+	fmt.Fprintf(&b, "serviceTime  %s\n", s.Stats.ServiceTime)
+	fmt.Fprintf(&b, "responseTime %s\n", s.Stats.ResponseTime)
+	for _, step := range s.Steps {
+		fmt.Fprintf(&b, "step %s total=%d success=%d fail=%d errorRate=%.2f%% serviceTime %s\n",
+			step.Name, step.Total, step.Success, step.Fail, step.ErrorRate, step.ServiceTime)
+	}
+	for _, variant := range s.Variants {
+		fmt.Fprintf(&b, "variant %s total=%d success=%d fail=%d errorRate=%.2f%% serviceTime %s\n",
+			variant.Name, variant.Total, variant.Success, variant.Fail, variant.ErrorRate, variant.ServiceTime)
+	}
+	for _, a := range s.Assertions {
+		fmt.Fprintf(&b, "assertion %s passed=%d failed=%d\n", a.Name, a.Passed, a.Failed)
+	}
+	return b.String()
+}
+
+func (l Latency) String() string {
+	return fmt.Sprintf("min=%.3fms avg=%.3fms p50=%.3fms p90=%.3fms p95=%.3fms p99=%.3fms max=%.3fms",
+		l.Min, l.Avg, l.P50, l.P90, l.P95, l.P99, l.Max)
+}
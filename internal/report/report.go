@@ -0,0 +1,503 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rk1165/loadsimulator/internal/load"
+	"github.com/rk1165/loadsimulator/internal/secret"
+	"github.com/rk1165/loadsimulator/internal/threshold"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FormatJSON  = "json"
+	FormatCSV   = "csv"
+	FormatJUnit = "junit"
+	FormatHTML  = "html"
+)
+
+// Report is the structured result of a load run, made of the results of each of its scenarios
+type Report struct {
+	GeneratedAt time.Time  `json:"generatedAt"`
+	Phase       string     `json:"phase,omitempty"` // phase of the suite the scenarios ran in, if any
+	Scenarios   []Scenario `json:"scenarios"`
+}
+
+// Scenario is the result of the run of one scenario
+type Scenario struct {
+	Name       string    `json:"name"`
+	ConfigType string    `json:"configType"`
+	SubConfig  string    `json:"subConfig"`
+	Config     any       `json:"config"` // the scenario as loaded from its config file
+	StartTime  time.Time `json:"startTime"`
+	DurationS  float64   `json:"durationSeconds"`
+	Aborted    string    `json:"aborted,omitempty"` // reason the run was stopped early, if it was
+	// Interrupted is set when the run was stopped by a signal, the results are those of what ran
+	Interrupted bool `json:"interrupted,omitempty"`
+	// Exhausted is set when the run stopped early as the rows of its sequential feeder were all used
+	Exhausted bool       `json:"exhausted,omitempty"`
+	Counters  Counters   `json:"counters"`
+	Stats     Stats      `json:"stats"`
+	Errors    Errors     `json:"errors"`
+	Timeline  []Interval `json:"timeline"`
+	Histogram []Bucket   `json:"histogram"` // distribution of the service times
+	// Thresholds are the outcome of the thresholds of the scenario, the scenario passes when all of them passed
+	Thresholds []threshold.Result `json:"thresholds"`
+	// Steps are the results of every step of a scenario made of several steps such as a journey
+	Steps []Step `json:"steps,omitempty"`
+	// Variants are the results of every payload variant of a scenario sending several
+	Variants []Step `json:"variants,omitempty"`
+	// Assertions are the results of every assertion on the responses, sorted by name
+	Assertions []Assertion `json:"assertions,omitempty"`
+}
+
+// Assertion is the number of responses which passed and failed an assertion
+type Assertion struct {
+	Name   string `json:"name"`
+	Passed uint64 `json:"passed"`
+	Failed uint64 `json:"failed"`
+}
+
+// Step is the result of a named part of a scenario, a step of a journey or a payload variant
+type Step struct {
+	Name        string                   `json:"name"`
+	Total       uint64                   `json:"total"`
+	Success     uint64                   `json:"success"`
+	Fail        uint64                   `json:"fail"`
+	ErrorRate   float64                  `json:"errorRate"`
+	ServiceTime Latency                  `json:"serviceTime"`
+	Errors      map[string]ErrorCategory `json:"errors,omitempty"`
+}
+
+// Counters are the counters of the runner
+type Counters struct {
+	Scheduled  uint64  `json:"scheduled"`
+	Started    uint64  `json:"started"`
+	Completed  uint64  `json:"completed"`
+	Failed     uint64  `json:"failed"`
+	Cancelled  uint64  `json:"cancelled"`
+	Throughput float64 `json:"throughput"`
+	TargetRate float64 `json:"targetRate"`
+}
+
+// Stats are the stats of the load with latencies in milliseconds
+type Stats struct {
+	Total        uint64  `json:"total"`
+	Success      uint64  `json:"success"`
+	Fail         uint64  `json:"fail"`
+	ErrorRate    float64 `json:"errorRate"` // percentage of failed requests
+	ServiceTime  Latency `json:"serviceTime"`
+	ResponseTime Latency `json:"responseTime"`
+}
+
+// Latency is a load.Latency in milliseconds
+type Latency struct {
+	Min float64 `json:"min"`
+	Avg float64 `json:"avg"`
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P95 float64 `json:"p95"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+// Errors breaks down the failed requests
+type Errors struct {
+	FailedResponses uint64 `json:"failedResponses"` // requests the load did not consider successful
+	ExecuteErrors   uint64 `json:"executeErrors"`   // requests whose execution returned an error
+	// Categories break down the failed responses and the execute errors, e.g. timeout or http 503
+	Categories map[string]ErrorCategory `json:"categories,omitempty"`
+	FirstError string                   `json:"firstError,omitempty"`
+	// Policy is the error policy of the scenario, with the fail policy any execute error fails the scenario
+	Policy string `json:"policy"`
+}
+
+// ErrorCategory counts the failures of a category with the message of one of them
+type ErrorCategory struct {
+	Count  uint64 `json:"count"`
+	Sample string `json:"sample,omitempty"`
+}
+
+// Bucket counts the service times between the upper bound of the previous bucket and UpToMs
+type Bucket struct {
+	UpToMs float64 `json:"upToMs"`
+	Count  uint64  `json:"count"`
+}
+
+// Interval is a load.Interval with latencies in milliseconds
+type Interval struct {
+	ElapsedS     float64 `json:"elapsedSeconds"`
+	Throughput   float64 `json:"throughput"`
+	Success      uint64  `json:"success"`
+	Fail         uint64  `json:"fail"`
+	ErrorRate    float64 `json:"errorRate"`
+	ServiceTime  Latency `json:"serviceTime"`
+	ResponseTime Latency `json:"responseTime"`
+}
+
+func New() *Report {
+	return &Report{GeneratedAt: time.Now()}
+}
+
+// Add adds the result of the run of a scenario to the report, its secrets masked before any
+// format escapes them
+func (r *Report) Add(name, configType, subConfig string, config any, runner *load.Runner, stats *load.Stats) {
+	summary := runner.Summary()
+	scenario := Scenario{
+		Name:        name,
+		ConfigType:  configType,
+		SubConfig:   subConfig,
+		Config:      echo(config),
+		StartTime:   summary.StartTime,
+		DurationS:   summary.Duration.Seconds(),
+		Aborted:     secret.Redact(summary.Aborted),
+		Interrupted: summary.Interrupted,
+		Exhausted:   summary.Exhausted,
+		Counters: Counters{
+			Scheduled:  summary.Scheduled,
+			Started:    summary.Started,
+			Completed:  summary.Completed,
+			Failed:     summary.Failed,
+			Cancelled:  summary.Cancelled,
+			Throughput: summary.Throughput,
+			TargetRate: summary.TargetRate,
+		},
+		Stats: Stats{
+			Total:        stats.Total,
+			Success:      stats.Success,
+			Fail:         stats.Fail,
+			ErrorRate:    errorRate(stats.Fail+summary.Failed, stats.Total+summary.Failed),
+			ServiceTime:  toLatency(stats.ServiceTime),
+			ResponseTime: toLatency(stats.ResponseTime),
+		},
+		Errors: Errors{
+			FailedResponses: stats.Fail,
+			ExecuteErrors:   summary.Failed,
+			Categories:      toCategories(stats.Failures),
+			FirstError:      secret.Redact(summary.FirstError),
+			Policy:          errorPolicy(runner.Cfg.ErrorPolicy),
+		},
+		Histogram: rebin(stats.Histogram),
+	}
+	scenario.Steps = toSteps(stats.Steps)
+	scenario.Variants = toSteps(stats.Variants)
+	scenario.Assertions = toAssertions(stats.Assertions)
+	for _, interval := range runner.Timeline() {
+		scenario.Timeline = append(scenario.Timeline, Interval{
+			ElapsedS:     interval.Elapsed.Seconds(),
+			Throughput:   interval.Throughput,
+			Success:      interval.Success,
+			Fail:         interval.Fail,
+			ErrorRate:    interval.ErrorRate,
+			ServiceTime:  toLatency(interval.ServiceTime),
+			ResponseTime: toLatency(interval.ResponseTime),
+		})
+	}
+	scenario.Thresholds = threshold.EvaluateAll(runner.Cfg.Thresholds, scenario.Metrics())
+	r.Scenarios = append(r.Scenarios, scenario)
+}
+
+// Metrics returns the metrics of the scenario by their threshold name
+func (s Scenario) Metrics() map[string]float64 {
+	return map[string]float64{
+		threshold.Total:       float64(s.Stats.Total),
+		threshold.Success:     float64(s.Stats.Success),
+		threshold.Fail:        float64(s.Stats.Fail),
+		threshold.Failed:      float64(s.Counters.Failed),
+		threshold.ErrorRate:   s.Stats.ErrorRate,
+		threshold.AchievedRps: s.Counters.Throughput,
+		threshold.Target:      s.Counters.TargetRate,
+		threshold.DurationS:   s.DurationS,
+		threshold.Min:         s.Stats.ServiceTime.Min,
+		threshold.Avg:         s.Stats.ServiceTime.Avg,
+		threshold.P50:         s.Stats.ServiceTime.P50,
+		threshold.P90:         s.Stats.ServiceTime.P90,
+		threshold.P95:         s.Stats.ServiceTime.P95,
+		threshold.P99:         s.Stats.ServiceTime.P99,
+		threshold.Max:         s.Stats.ServiceTime.Max,
+		threshold.ResponseMin: s.Stats.ResponseTime.Min,
+		threshold.ResponseAvg: s.Stats.ResponseTime.Avg,
+		threshold.ResponseP50: s.Stats.ResponseTime.P50,
+		threshold.ResponseP90: s.Stats.ResponseTime.P90,
+		threshold.ResponseP95: s.Stats.ResponseTime.P95,
+		threshold.ResponseP99: s.Stats.ResponseTime.P99,
+		threshold.ResponseMax: s.Stats.ResponseTime.Max,
+	}
+}
+
+// FailedByErrors tells whether the execute errors of the scenario fail it under its error policy
+func (s Scenario) FailedByErrors() bool {
+	return s.Errors.Policy == load.ErrorPolicyFail && s.Errors.ExecuteErrors > 0
+}
+
+// check is a pass or fail verdict on a scenario, a test case of the JUnit report
+type check struct {
+	name    string
+	kind    string
+	message string // why the check failed, empty when it passed
+	detail  string
+}
+
+// checks returns the verdicts a scenario is judged on, by the exit code and the JUnit report alike:
+// an aborted scenario fails, so does a request which returned an error under the fail error
+// policy. The thresholds then decide whether the failed responses fail the scenario, without
+// thresholds any failed request or assertion fails it
+func (s Scenario) checks() []check {
+	var checks []check
+	if s.Aborted != "" {
+		checks = append(checks, check{name: "aborted", kind: "abort", message: "run aborted", detail: s.Aborted})
+	}
+	if s.Errors.Policy == load.ErrorPolicyFail {
+		c := check{name: "errors", kind: "errors"}
+		if s.FailedByErrors() {
+			c.message = fmt.Sprintf("%d request(s) failed with an error", s.Errors.ExecuteErrors)
+			c.detail = s.Errors.FirstError
+		}
+		checks = append(checks, c)
+	}
+	if len(s.Thresholds) > 0 {
+		for _, result := range s.Thresholds {
+			c := check{name: result.Expression, kind: "threshold"}
+			if !result.Passed {
+				c.message = fmt.Sprintf("threshold %s violated", result.Expression)
+				c.detail = fmt.Sprintf("actual=%.3f expected=%.3f", result.Actual, result.Expected)
+			}
+			checks = append(checks, c)
+		}
+		return checks
+	}
+	for _, a := range s.Assertions {
+		c := check{name: "assertion " + a.Name, kind: "assertion"}
+		if a.Failed > 0 {
+			c.message = fmt.Sprintf("%d of %d responses failed the assertion", a.Failed, a.Passed+a.Failed)
+		}
+		checks = append(checks, c)
+	}
+	c := check{name: "requests", kind: "requests"}
+	if failed := s.Stats.Fail + s.Counters.Failed; failed > 0 {
+		c.message = fmt.Sprintf("%d of %d requests failed", failed, s.Stats.Total+s.Counters.Failed)
+		c.detail = s.Errors.FirstError
+	}
+	return append(checks, c)
+}
+
+// Violations returns the failed checks of all the scenarios, see checks
+func (r *Report) Violations() []string {
+	var violations []string
+	for _, s := range r.Scenarios {
+		for _, c := range s.checks() {
+			switch {
+			case c.message == "":
+			case c.detail == "":
+				violations = append(violations, fmt.Sprintf("scenario=%s %s", s.Name, c.message))
+			default:
+				violations = append(violations, fmt.Sprintf("scenario=%s %s: %s", s.Name, c.message, c.detail))
+			}
+		}
+	}
+	return violations
+}
+
+// Interrupted tells whether any of the scenarios was interrupted
+func (r *Report) Interrupted() bool {
+	for _, s := range r.Scenarios {
+		if s.Interrupted {
+			return true
+		}
+	}
+	return false
+}
+
+// Write writes the report to file in the given format, or in the format matching the
+// extension of the file when format is empty (.json, .csv, .xml for JUnit or .html)
+func (r *Report) Write(file string, format string) error {
+	if format == "" {
+		format = formatOf(file)
+	}
+	var content []byte
+	var err error
+	switch format {
+	case FormatJSON:
+		content, err = json.MarshalIndent(r, "", "  ")
+	case FormatCSV:
+		content, err = r.csv()
+	case FormatJUnit:
+		content, err = r.junit()
+	case FormatHTML:
+		content, err = r.html()
+	default:
+		return fmt.Errorf("unknown report format %q for file=%s", format, file)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to build %s report error=[%v]", format, err)
+	}
+	if dir := filepath.Dir(file); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create report directory=%s error=[%v]", dir, err)
+		}
+	}
+	if err := os.WriteFile(file, content, 0644); err != nil {
+		return fmt.Errorf("failed to write report file=%s error=[%v]", file, err)
+	}
+	return nil
+}
+
+// echo returns the config with the keys and values it has in the scenario files, its secrets
+// masked
+func echo(config any) any {
+	b, err := yaml.Marshal(config)
+	if err != nil {
+		return config
+	}
+	var echoed map[string]any
+	if err := yaml.Unmarshal(b, &echoed); err != nil {
+		return config
+	}
+	return redact(echoed)
+}
+
+// redact masks the values of the sensitive keys and the registered secrets of a config
+func redact(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, value := range v {
+			if s, ok := value.(string); ok && s != "" && secret.IsSensitive(k) {
+				v[k] = secret.Mask
+			} else {
+				v[k] = redact(value)
+			}
+		}
+		if key, ok := v["key"].(string); ok && secret.IsSensitive(key) {
+			if _, ok := v["value"].(string); ok {
+				v["value"] = secret.Mask
+			}
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = redact(value)
+		}
+	case string:
+		return secret.Redact(v)
+	}
+	return v
+}
+
+func formatOf(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".csv":
+		return FormatCSV
+	case ".xml":
+		return FormatJUnit
+	case ".html", ".htm":
+		return FormatHTML
+	default:
+		return FormatJSON
+	}
+}
+
+func toCategories(failures map[string]load.ErrorCount) map[string]ErrorCategory {
+	if len(failures) == 0 {
+		return nil
+	}
+	categories := make(map[string]ErrorCategory, len(failures))
+	for category, count := range failures {
+		categories[category] = ErrorCategory{Count: count.Count, Sample: secret.Redact(count.Sample)}
+	}
+	return categories
+}
+
+func toSteps(parts []load.StepStats) []Step {
+	var steps []Step
+	for _, part := range parts {
+		steps = append(steps, Step{
+			Name:        part.Name,
+			Total:       part.Stats.Total,
+			Success:     part.Stats.Success,
+			Fail:        part.Stats.Fail,
+			ErrorRate:   errorRate(part.Stats.Fail, part.Stats.Total),
+			ServiceTime: toLatency(part.Stats.ServiceTime),
+			Errors:      toCategories(part.Stats.Failures),
+		})
+	}
+	return steps
+}
+
+func toAssertions(counts map[string]load.AssertionCount) []Assertion {
+	var assertions []Assertion
+	for name, c := range counts {
+		assertions = append(assertions, Assertion{Name: name, Passed: c.Passed, Failed: c.Failed})
+	}
+	sort.Slice(assertions, func(i, j int) bool { return assertions[i].Name < assertions[j].Name })
+	return assertions
+}
+
+func toLatency(l load.Latency) Latency {
+	return Latency{
+		Min: millis(l.MinTime),
+		Avg: millis(l.AvgTime),
+		P50: millis(l.P50),
+		P90: millis(l.P90),
+		P95: millis(l.P95),
+		P99: millis(l.P99),
+		Max: millis(l.MaxTime),
+	}
+}
+
+// rebin groups the fine grained buckets of a histogram into buckets bounded by 1, 2 and 5
+// times the powers of ten milliseconds, from the first to the last non-empty one
+func rebin(buckets []load.Bucket) []Bucket {
+	if len(buckets) == 0 {
+		return nil
+	}
+	var bounds []float64
+	// from 10µs to 5000s which is above load.HighestTrackable, smaller values fall in the first bucket
+	for exp := -2; exp <= 6; exp++ {
+		decade := math.Pow10(exp)
+		bounds = append(bounds, decade, 2*decade, 5*decade)
+	}
+	rebinned := make([]Bucket, len(bounds))
+	for i, bound := range bounds {
+		rebinned[i].UpToMs = bound
+	}
+	for _, b := range buckets {
+		upTo := millis(b.To)
+		i := 0
+		for i < len(bounds)-1 && bounds[i] < upTo {
+			i++
+		}
+		rebinned[i].Count += b.Count
+	}
+	first, last := 0, len(rebinned)-1
+	for rebinned[first].Count == 0 {
+		first++
+	}
+	for rebinned[last].Count == 0 {
+		last--
+	}
+	return rebinned[first : last+1]
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func errorPolicy(policy string) string {
+	if policy == "" {
+		return load.ErrorPolicyFail
+	}
+	return policy
+}
+
+func errorRate(failed, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(failed) * 100 / float64(total)
+}
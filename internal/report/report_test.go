@@ -0,0 +1,97 @@
+package report
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/rk1165/loadsimulator/internal/load"
+	"github.com/rk1165/loadsimulator/internal/threshold"
+)
+
+func TestVerdict(t *testing.T) {
+	tests := []struct {
+		name       string
+		scenario   Scenario
+		violations int
+	}{
+		{
+			name:     "passed",
+			scenario: Scenario{Stats: Stats{Total: 10, Success: 10}},
+		},
+		{
+			name:       "failed request without thresholds",
+			scenario:   Scenario{Stats: Stats{Total: 10, Success: 9, Fail: 1}},
+			violations: 1,
+		},
+		{
+			name:       "failed request and assertion without thresholds",
+			scenario:   Scenario{Stats: Stats{Total: 10, Success: 9, Fail: 1}, Assertions: []Assertion{{Name: "status", Passed: 9, Failed: 1}}},
+			violations: 2,
+		},
+		{
+			name: "failed request within the thresholds",
+			scenario: Scenario{
+				Stats:      Stats{Total: 10, Success: 9, Fail: 1},
+				Assertions: []Assertion{{Name: "status", Passed: 9, Failed: 1}},
+				Thresholds: []threshold.Result{{Expression: "errorRate < 20%", Passed: true}},
+			},
+		},
+		{
+			name: "violated threshold",
+			scenario: Scenario{
+				Stats:      Stats{Total: 10, Success: 10},
+				Thresholds: []threshold.Result{{Expression: "p95 < 1ms", Actual: 2, Expected: 1}, {Expression: "errorRate < 1%", Passed: true}},
+			},
+			violations: 1,
+		},
+		{
+			name: "execute errors under the fail policy",
+			scenario: Scenario{
+				Counters:   Counters{Failed: 1},
+				Stats:      Stats{Total: 10, Success: 10},
+				Errors:     Errors{ExecuteErrors: 1, Policy: load.ErrorPolicyFail},
+				Thresholds: []threshold.Result{{Expression: "errorRate < 20%", Passed: true}},
+			},
+			violations: 1,
+		},
+		{
+			name: "execute errors ignored",
+			scenario: Scenario{
+				Counters:   Counters{Failed: 1},
+				Stats:      Stats{Total: 10, Success: 10},
+				Errors:     Errors{ExecuteErrors: 1, Policy: load.ErrorPolicyIgnore},
+				Thresholds: []threshold.Result{{Expression: "errorRate < 20%", Passed: true}},
+			},
+		},
+		{
+			name: "aborted",
+			scenario: Scenario{
+				Aborted:    "p95 > 1s over 10s",
+				Stats:      Stats{Total: 10, Success: 10},
+				Thresholds: []threshold.Result{{Expression: "errorRate < 20%", Passed: true}},
+			},
+			violations: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.scenario.Name = "orders"
+			r := &Report{Scenarios: []Scenario{tt.scenario}}
+			violations := r.Violations()
+			if len(violations) != tt.violations {
+				t.Errorf("Violations() = %q, want %d", violations, tt.violations)
+			}
+			content, err := r.junit()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var suites junitSuites
+			if err := xml.Unmarshal(content, &suites); err != nil {
+				t.Fatal(err)
+			}
+			if suites.Failures != len(violations) {
+				t.Errorf("junit has %d failures, Violations() = %q", suites.Failures, violations)
+			}
+		})
+	}
+}
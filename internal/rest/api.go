@@ -0,0 +1,201 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rk1165/loadsimulator/internal/feeder"
+	"github.com/rk1165/loadsimulator/internal/load"
+	"github.com/rk1165/loadsimulator/internal/logger"
+	"github.com/rk1165/loadsimulator/internal/payload"
+	"github.com/rk1165/loadsimulator/internal/template"
+	"github.com/rk1165/loadsimulator/internal/types"
+)
+
+// LoadApi calls an HTTP endpoint with the method of its scenario, GET when not set. The url, the
+// header values and the body are templates rendered for every request, the body is picked from
+// the payload variants of the scenario
+type LoadApi struct {
+	load.BaseLoad
+	method             string
+	url                *endpoint
+	headers            map[string]*template.Template
+	payloads           *payload.Pool[*template.Template] // a nil template for an empty body
+	expectedStatusCode int
+	log                load.Log
+	replaceParams      []types.KV
+	client             *http.Client
+	feeder             *feeder.Feeder
+	assertions         []assertion
+	readBody           bool // whether an assertion needs the body of the response
+}
+
+func NewApi(apiConfig types.ApiConfig, cfg types.Config) (*LoadApi, error) {
+	client, err := newClient(apiConfig.Client)
+	if err != nil {
+		return nil, err
+	}
+	assertions, err := compileAssertions(apiConfig.Assertions, apiConfig.ExpectedStatusCode)
+	if err != nil {
+		return nil, err
+	}
+	url, err := newEndpoint(apiConfig.ResolvePath(), apiConfig.QueryParams)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := parseHeaders(resolveHeaders(apiConfig))
+	if err != nil {
+		return nil, err
+	}
+	apiLoad := &LoadApi{
+		method:             strings.ToUpper(apiConfig.Method),
+		url:                url,
+		expectedStatusCode: apiConfig.ExpectedStatusCode,
+		headers:            headers,
+		BaseLoad:           load.NewBaseLoad(cfg),
+		log:                logger.CreateLoadLog(cfg.Name),
+		replaceParams:      apiConfig.ReplaceParams,
+		client:             client,
+		assertions:         assertions,
+		readBody:           needsBody(assertions),
+	}
+	if apiLoad.method == "" {
+		apiLoad.method = http.MethodGet
+	}
+	apiLoad.log.InfoLog.Printf("[HTTP %s] url=%s", apiLoad.method, apiConfig.ResolvePath())
+	if apiLoad.feeder, err = newFeeder(apiConfig, cfg, apiLoad.log); err != nil {
+		return nil, err
+	}
+	base := apiConfig.BaseConfig
+	if !hasBody(apiLoad.method) {
+		base.FileName, base.Payloads = "", nil
+	}
+	apiLoad.payloads, err = payload.New(base, cfg, func(file, content string) (*template.Template, error) {
+		if content == "" {
+			return nil, nil
+		}
+		return template.Parse(content)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("body: %v", err)
+	}
+	if len(base.Payloads) > 0 {
+		apiLoad.log.InfoLog.Printf("[PAYLOADS] %s", apiLoad.payloads)
+	}
+	return apiLoad, nil
+}
+
+// hasBody tells whether a request with the method carries the body of the scenario
+func hasBody(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodConnect:
+		return false
+	default:
+		return true
+	}
+}
+
+func (a *LoadApi) Execute(ctx context.Context, id uint64) error {
+	// render the templates with the row of the feeder, then replace the fields of replaceParams
+	// in the body and the headers
+	c := template.Context{ID: id}
+	if a.feeder != nil {
+		row, err := a.feeder.Next(ctx)
+		if err != nil {
+			return err
+		}
+		c.Vars = row
+	}
+	replacer := generate(a.replaceParams)
+	variant, bodyTemplate := a.payloads.Pick()
+	var body io.Reader
+	if bodyTemplate != nil {
+		newBody := bodyTemplate.Execute(c)
+		if replacer != nil {
+			newBody = replacer.Replace(newBody)
+		}
+		body = strings.NewReader(newBody)
+	}
+	req, err := http.NewRequestWithContext(ctx, a.method, a.url.render(c), body)
+
+	if err != nil {
+		return err
+	}
+
+	for k, t := range a.headers {
+		v := t.Execute(c)
+		if replacer != nil {
+			v = replacer.Replace(v)
+		}
+		req.Header.Set(k, v)
+	}
+
+	start := time.Now()
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	r := &response{Response: resp}
+	if a.readBody {
+		r.body, err = io.ReadAll(resp.Body)
+		r.size = int64(len(r.body))
+	} else {
+		r.size, err = io.Copy(io.Discard, resp.Body)
+	}
+	resp.Body.Close()
+	duration := time.Since(start)
+	if err != nil {
+		return err
+	}
+	r.latency = duration
+
+	category, message := a.check(r)
+	if category == "" {
+		a.Record(duration, true)
+		variant.Stats.Record(duration, true)
+		a.log.InfoLog.Printf("[HTTP %s] requestId=%d status=%d elapsed=%s", a.method, id, resp.StatusCode, duration)
+	} else {
+		a.RecordFailure(duration, category, message)
+		variant.Stats.RecordFailure(duration, category, message)
+		a.log.ErrorLog.Printf("[HTTP %s] requestId=%d status=%d elapsed=%s %s", a.method, id, resp.StatusCode, duration, message)
+	}
+	return nil
+}
+
+// check runs every assertion against the response and counts their results. It returns the
+// failure category and message of the first failed assertion, empty when all of them passed
+func (a *LoadApi) check(r *response) (string, string) {
+	var category, message string
+	for _, assertion := range a.assertions {
+		err := assertion.check(r)
+		a.RecordAssertion(assertion.name, err == nil)
+		if err == nil || category != "" {
+			continue
+		}
+		message = err.Error()
+		if assertion.name == AssertionStatus {
+			category = statusCategory(r.StatusCode)
+			message = r.Status
+		} else {
+			category = "assertion " + assertion.name
+		}
+	}
+	return category, message
+}
+
+func (a *LoadApi) Success(resp any) bool {
+	apiResponse := resp.(*http.Response)
+	return a.assertions[0].check(&response{Response: apiResponse}) == nil
+}
+
+// CalculateStats returns the stats of the load along with the stats of every payload variant
+func (a *LoadApi) CalculateStats() *load.Stats {
+	stats := a.BaseLoad.CalculateStats()
+	stats.Variants = a.payloads.Stats()
+	return stats
+}
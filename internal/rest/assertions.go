@@ -0,0 +1,182 @@
+package rest
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rk1165/loadsimulator/internal/extract"
+	"github.com/rk1165/loadsimulator/internal/schema"
+	"github.com/rk1165/loadsimulator/internal/types"
+)
+
+// AssertionStatus is the name of the assertion on the status code
+const AssertionStatus = "status"
+
+// response is what the assertions check
+type response struct {
+	*http.Response
+	body    []byte // only read when an assertion needs it
+	size    int64
+	latency time.Duration
+}
+
+// assertion is a named check of a response
+type assertion struct {
+	name      string
+	needsBody bool
+	check     func(r *response) error
+}
+
+// statusRange accepts the status codes from min to max
+type statusRange struct {
+	min, max int
+}
+
+// compileAssertions builds the assertions of a scenario. The status assertion comes first, it
+// accepts expectedStatusCode when no status is configured, any 2xx when neither is
+func compileAssertions(a types.Assertions, expectedStatusCode int) ([]assertion, error) {
+	ranges, err := parseStatus(a.Status, expectedStatusCode)
+	if err != nil {
+		return nil, err
+	}
+	assertions := []assertion{{name: AssertionStatus, check: func(r *response) error {
+		if !acceptsStatus(ranges, r.StatusCode) {
+			return fmt.Errorf("unexpected status %s", r.Status)
+		}
+		return nil
+	}}}
+	for _, h := range a.Headers {
+		assertions = append(assertions, assertion{name: "header " + h.Key, check: func(r *response) error {
+			value := r.Header.Get(h.Key)
+			if value == "" {
+				return fmt.Errorf("missing header %s", h.Key)
+			}
+			if !strings.Contains(value, h.Value) {
+				return fmt.Errorf("header %s=%q does not contain %q", h.Key, value, h.Value)
+			}
+			return nil
+		}})
+	}
+	for _, s := range a.BodyContains {
+		assertions = append(assertions, assertion{name: "bodyContains " + s, needsBody: true, check: func(r *response) error {
+			if !bytes.Contains(r.body, []byte(s)) {
+				return fmt.Errorf("body does not contain %q", s)
+			}
+			return nil
+		}})
+	}
+	for _, expr := range a.BodyRegex {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bodyRegex %q error=[%v]", expr, err)
+		}
+		assertions = append(assertions, assertion{name: "bodyRegex " + expr, needsBody: true, check: func(r *response) error {
+			if !re.Match(r.body) {
+				return fmt.Errorf("body does not match %q", expr)
+			}
+			return nil
+		}})
+	}
+	for _, p := range a.JSONPath {
+		assertions = append(assertions, pathAssertion("jsonPath", p, extract.JSONPath))
+	}
+	for _, p := range a.XPath {
+		assertions = append(assertions, pathAssertion("xPath", p, extract.XPath))
+	}
+	if a.JSONSchema != "" {
+		s, err := schema.Compile(a.ResolveSchema())
+		if err != nil {
+			return nil, fmt.Errorf("jsonSchema %s: %v", a.JSONSchema, err)
+		}
+		assertions = append(assertions, assertion{name: "jsonSchema", needsBody: true, check: func(r *response) error {
+			return s.Validate(r.body)
+		}})
+	}
+	if a.MaxBodySize > 0 {
+		assertions = append(assertions, assertion{name: "maxBodySize", check: func(r *response) error {
+			if r.size > a.MaxBodySize {
+				return fmt.Errorf("body of %d bytes above %d", r.size, a.MaxBodySize)
+			}
+			return nil
+		}})
+	}
+	if a.MaxLatency > 0 {
+		assertions = append(assertions, assertion{name: "maxLatency", check: func(r *response) error {
+			if r.latency > a.MaxLatency {
+				return fmt.Errorf("latency of %s above %s", r.latency, a.MaxLatency)
+			}
+			return nil
+		}})
+	}
+	return assertions, nil
+}
+
+func pathAssertion(kind string, p types.PathEquals, value func([]byte, string) (string, error)) assertion {
+	return assertion{name: kind + " " + p.Path, needsBody: true, check: func(r *response) error {
+		actual, err := value(r.body, p.Path)
+		if err != nil {
+			return err
+		}
+		if actual != p.Equals {
+			return fmt.Errorf("%s is %q, expected %q", p.Path, actual, p.Equals)
+		}
+		return nil
+	}}
+}
+
+// parseStatus reads status codes (200), ranges (200-204) and classes (2xx)
+func parseStatus(status []string, expectedStatusCode int) ([]statusRange, error) {
+	if len(status) == 0 {
+		if expectedStatusCode == 0 {
+			return []statusRange{{200, 299}}, nil
+		}
+		return []statusRange{{expectedStatusCode, expectedStatusCode}}, nil
+	}
+	var ranges []statusRange
+	for _, s := range status {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if class, ok := strings.CutSuffix(s, "xx"); ok {
+			c, err := strconv.Atoi(class)
+			if err != nil || c < 1 || c > 5 {
+				return nil, fmt.Errorf("invalid status %q", s)
+			}
+			ranges = append(ranges, statusRange{c * 100, c*100 + 99})
+			continue
+		}
+		from, to, isRange := strings.Cut(s, "-")
+		if !isRange {
+			to = from
+		}
+		min, errMin := strconv.Atoi(strings.TrimSpace(from))
+		max, errMax := strconv.Atoi(strings.TrimSpace(to))
+		if errMin != nil || errMax != nil || min > max {
+			return nil, fmt.Errorf("invalid status %q", s)
+		}
+		ranges = append(ranges, statusRange{min, max})
+	}
+	return ranges, nil
+}
+
+func acceptsStatus(ranges []statusRange, code int) bool {
+	for _, r := range ranges {
+		if code >= r.min && code <= r.max {
+			return true
+		}
+	}
+	return false
+}
+
+// needsBody tells whether any of the assertions reads the body
+func needsBody(assertions []assertion) bool {
+	for _, a := range assertions {
+		if a.needsBody {
+			return true
+		}
+	}
+	return false
+}
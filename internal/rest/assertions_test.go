@@ -0,0 +1,39 @@
+package rest
+
+import "testing"
+
+func TestParseStatus(t *testing.T) {
+	tests := []struct {
+		status   []string
+		expected int
+		accepts  []int
+		rejects  []int
+		wantErr  bool
+	}{
+		{accepts: []int{200, 204, 299}, rejects: []int{199, 300, 404}},
+		{expected: 201, accepts: []int{201}, rejects: []int{200, 202}},
+		{status: []string{"200", "404"}, expected: 201, accepts: []int{200, 404}, rejects: []int{201}},
+		{status: []string{"200-204"}, accepts: []int{200, 202, 204}, rejects: []int{205}},
+		{status: []string{" 4XX "}, accepts: []int{400, 499}, rejects: []int{399, 500}},
+		{status: []string{"6xx"}, wantErr: true},
+		{status: []string{"204-200"}, wantErr: true},
+		{status: []string{"ok"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		ranges, err := parseStatus(tt.status, tt.expected)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseStatus(%q) error = %v, wantErr %v", tt.status, err, tt.wantErr)
+			continue
+		}
+		for _, code := range tt.accepts {
+			if !acceptsStatus(ranges, code) {
+				t.Errorf("parseStatus(%q, %d) rejects %d", tt.status, tt.expected, code)
+			}
+		}
+		for _, code := range tt.rejects {
+			if acceptsStatus(ranges, code) {
+				t.Errorf("parseStatus(%q, %d) accepts %d", tt.status, tt.expected, code)
+			}
+		}
+	}
+}
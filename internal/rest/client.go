@@ -0,0 +1,72 @@
+package rest
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/rk1165/loadsimulator/internal/types"
+)
+
+// newClient builds the client of a scenario from its config
+func newClient(cfg types.HTTPClientConfig) (*http.Client, error) {
+	if cfg.Timeout < 0 || cfg.DialTimeout < 0 || cfg.TLSHandshakeTimeout < 0 || cfg.IdleConnTimeout < 0 {
+		return nil, fmt.Errorf("invalid client, negative timeout")
+	}
+	if cfg.MaxIdleConns < 0 || cfg.MaxIdleConnsPerHost < 0 || cfg.MaxConnsPerHost < 0 {
+		return nil, fmt.Errorf("invalid client, negative number of connections")
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.DialTimeout > 0 {
+		dialer := &net.Dialer{Timeout: cfg.DialTimeout, KeepAlive: 30 * time.Second}
+		transport.DialContext = dialer.DialContext
+	}
+	if cfg.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = cfg.TLSHandshakeTimeout
+	}
+	if cfg.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = cfg.IdleConnTimeout
+	}
+	if cfg.MaxIdleConns > 0 {
+		transport.MaxIdleConns = cfg.MaxIdleConns
+	}
+	transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	transport.MaxConnsPerHost = cfg.MaxConnsPerHost
+	transport.DisableKeepAlives = cfg.DisableKeepAlives
+
+	protocols := new(http.Protocols)
+	switch cfg.HTTP2 {
+	case types.HTTP2Auto:
+		protocols.SetHTTP1(true)
+		protocols.SetHTTP2(true)
+	case types.HTTP2Force:
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+	case types.HTTP2Disable:
+		protocols.SetHTTP1(true)
+	default:
+		return nil, fmt.Errorf("invalid client http2 %q, expected %q or %q", cfg.HTTP2, types.HTTP2Force, types.HTTP2Disable)
+	}
+	transport.Protocols = protocols
+
+	switch cfg.Proxy {
+	case "":
+		transport.Proxy = http.ProxyFromEnvironment
+	case types.ProxyNone:
+		transport.Proxy = nil
+	default:
+		proxy, err := url.Parse(cfg.Proxy)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("invalid client proxy %q", cfg.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = types.DefaultHTTPTimeout
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}, nil
+}
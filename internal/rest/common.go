@@ -3,8 +3,17 @@ package rest
 import (
 	"fmt"
 	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/rk1165/loadsimulator/internal"
+	"github.com/rk1165/loadsimulator/internal/feeder"
+	"github.com/rk1165/loadsimulator/internal/load"
+	"github.com/rk1165/loadsimulator/internal/template"
 	"github.com/rk1165/loadsimulator/internal/types"
 )
 
@@ -22,5 +31,107 @@ func resolveHeaders(apiConfig types.ApiConfig) map[string]string {
 	headers := make(map[string]string)
 	headers["Authorization"] = fmt.Sprintf("Bearer %s", token)
 	headers["Content-Type"] = apiConfig.ContentType
+	for _, h := range apiConfig.Headers {
+		headers[h.Key] = h.Value
+	}
 	return headers
 }
+
+// Values of ReplaceParams generated for every request
+const (
+	ReplaceUUID        = "UUID"         // a random UUID, e.g. for correlation ids and idempotency keys
+	ReplaceTimestamp   = "TIMESTAMP"    // the current time in RFC 3339 with milliseconds
+	ReplaceEpochMillis = "EPOCH_MILLIS" // the current time in milliseconds since the epoch
+)
+
+// generate generates the values of the params for a request, the same value replaces a key in
+// the body and in the headers of the request
+func generate(params []types.KV) *strings.Replacer {
+	if len(params) == 0 {
+		return nil
+	}
+	now := time.Now()
+	var oldnew []string
+	for _, v := range params {
+		switch v.Value {
+		case ReplaceUUID:
+			oldnew = append(oldnew, v.Key, uuid.New().String())
+		case ReplaceTimestamp:
+			oldnew = append(oldnew, v.Key, now.Format("2006-01-02T15:04:05.000Z07:00"))
+		case ReplaceEpochMillis:
+			oldnew = append(oldnew, v.Key, strconv.FormatInt(now.UnixMilli(), 10))
+		}
+	}
+	return strings.NewReplacer(oldnew...)
+}
+
+// endpoint is a url whose path and query param values are templates
+type endpoint struct {
+	path  *template.Template
+	query []queryParam
+}
+
+type queryParam struct {
+	key   string
+	value *template.Template
+}
+
+func newEndpoint(path string, queryParams []types.KV) (*endpoint, error) {
+	t, err := template.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	e := &endpoint{path: t}
+	for _, v := range queryParams {
+		value, err := template.Parse(v.Value)
+		if err != nil {
+			return nil, fmt.Errorf("query param %s: %v", v.Key, err)
+		}
+		e.query = append(e.query, queryParam{key: v.Key, value: value})
+	}
+	return e, nil
+}
+
+// render renders the url of a request, the values of the query params are escaped
+func (e *endpoint) render(c template.Context) string {
+	u := e.path.Execute(c)
+	if len(e.query) == 0 {
+		return u
+	}
+	queryParams := make([]string, 0, len(e.query))
+	for _, q := range e.query {
+		queryParams = append(queryParams, fmt.Sprintf("%s=%s", q.key, url.QueryEscape(q.value.Execute(c))))
+	}
+	return u + "?" + strings.Join(queryParams, "&")
+}
+
+// parseHeaders parses the values of the headers as templates
+func parseHeaders(headers map[string]string) (map[string]*template.Template, error) {
+	templates := make(map[string]*template.Template, len(headers))
+	for k, v := range headers {
+		t, err := template.Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("header %s: %v", k, err)
+		}
+		templates[k] = t
+	}
+	return templates, nil
+}
+
+// newFeeder reads the rows of the feeder of a scenario, nil when it has none
+func newFeeder(apiConfig types.ApiConfig, cfg types.Config, log load.Log) (*feeder.Feeder, error) {
+	if apiConfig.Feeder == nil {
+		return nil, nil
+	}
+	f, err := feeder.New(*apiConfig.Feeder, cfg.Concurrency)
+	if err != nil {
+		return nil, err
+	}
+	log.InfoLog.Printf("[FEEDER] %s", f)
+	return f, nil
+}
+
+// statusCategory is the failure category of a response with an unexpected status code
+func statusCategory(code int) string {
+	return fmt.Sprintf("http %d", code)
+}
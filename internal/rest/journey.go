@@ -0,0 +1,287 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rk1165/loadsimulator/internal/extract"
+	"github.com/rk1165/loadsimulator/internal/feeder"
+	"github.com/rk1165/loadsimulator/internal/load"
+	"github.com/rk1165/loadsimulator/internal/logger"
+	"github.com/rk1165/loadsimulator/internal/template"
+	"github.com/rk1165/loadsimulator/internal/types"
+)
+
+// CategoryExtract is the failure category of a response a value could not be extracted from
+const CategoryExtract = "extract"
+
+// LoadJourney executes the steps of a journey one after the other for every request. The values
+// extracted from the response of a step are variables of the templates of the following ones
+type LoadJourney struct {
+	load.BaseLoad
+	headers map[string]*template.Template
+	steps   []*journeyStep
+	client  *http.Client
+	feeder  *feeder.Feeder
+	log     load.Log
+}
+
+type journeyStep struct {
+	types.JourneyStep
+	url     *endpoint
+	headers []stepHeader
+	body    *template.Template
+	regexes []*regexp.Regexp // compiled regex of every extraction, nil for the other kinds
+	stats   *load.PartStats
+}
+
+type stepHeader struct {
+	key   string
+	value *template.Template
+}
+
+func NewJourney(journeyConfig types.JourneyConfig, cfg types.Config) (*LoadJourney, error) {
+	if len(journeyConfig.Steps) == 0 {
+		return nil, errors.New("journey without steps")
+	}
+	if keys := stepKeys(journeyConfig); len(keys) > 0 {
+		return nil, fmt.Errorf("journey: unsupported %s, the steps set their own method, endpoint, params, body and "+
+			"expected status", strings.Join(keys, ", "))
+	}
+	client, err := newClient(journeyConfig.Client)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := parseHeaders(resolveHeaders(journeyConfig.ApiConfig))
+	if err != nil {
+		return nil, err
+	}
+	journey := &LoadJourney{
+		headers:  headers,
+		client:   client,
+		BaseLoad: load.NewBaseLoad(cfg),
+		log:      logger.CreateLoadLog(cfg.Name),
+	}
+	if journey.feeder, err = newFeeder(journeyConfig.ApiConfig, cfg, journey.log); err != nil {
+		return nil, err
+	}
+	for i, s := range journeyConfig.Steps {
+		if s.Name == "" {
+			s.Name = fmt.Sprintf("step%d", i+1)
+		}
+		if s.Method == "" {
+			s.Method = http.MethodGet
+		}
+		step := &journeyStep{JourneyStep: s, stats: load.NewPartStats(cfg)}
+		if err := step.parse(journeyConfig.BaseUrl); err != nil {
+			return nil, fmt.Errorf("step %s: %v", s.Name, err)
+		}
+		for _, e := range s.Extract {
+			re, err := compileExtraction(e)
+			if err != nil {
+				return nil, fmt.Errorf("step %s: %v", s.Name, err)
+			}
+			step.regexes = append(step.regexes, re)
+		}
+		journey.steps = append(journey.steps, step)
+	}
+	return journey, nil
+}
+
+// stepKeys returns the keys of an http scenario set on a journey, which the journey would ignore
+func stepKeys(c types.JourneyConfig) []string {
+	var keys []string
+	add := func(key string, set bool) {
+		if set {
+			keys = append(keys, key)
+		}
+	}
+	add("method", c.Method != "")
+	add("endpoint", c.Endpoint != "")
+	add("pathVariables", len(c.PathVariables) > 0)
+	add("queryParams", len(c.QueryParams) > 0)
+	add("expectedStatusCode", c.ExpectedStatusCode != 0)
+	add("replaceParams", len(c.ReplaceParams) > 0)
+	add("assertions", !reflect.ValueOf(c.Assertions).IsZero())
+	add("fileName", c.FileName != "")
+	add("payloads", len(c.Payloads) > 0)
+	return keys
+}
+
+// parse parses the url, the header values and the body of the step as templates
+func (s *journeyStep) parse(baseUrl string) error {
+	var err error
+	if s.url, err = newEndpoint(baseUrl+s.Endpoint, s.QueryParams); err != nil {
+		return err
+	}
+	for _, h := range s.Headers {
+		value, err := template.Parse(h.Value)
+		if err != nil {
+			return fmt.Errorf("header %s: %v", h.Key, err)
+		}
+		s.headers = append(s.headers, stepHeader{key: h.Key, value: value})
+	}
+	if body := s.ResolveBody(); body != "" {
+		if s.body, err = template.Parse(body); err != nil {
+			return fmt.Errorf("body: %v", err)
+		}
+	}
+	return nil
+}
+
+// compileExtraction checks the extraction has a variable and a single source and compiles its regex if any
+func compileExtraction(e types.Extraction) (*regexp.Regexp, error) {
+	if e.Variable == "" {
+		return nil, errors.New("extraction without variable")
+	}
+	sources := 0
+	for _, source := range []string{e.JSONPath, e.XPath, e.Regex, e.Header} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return nil, fmt.Errorf("variable %s needs exactly one of jsonPath, xPath, regex or header", e.Variable)
+	}
+	if e.Regex == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(e.Regex)
+	if err != nil {
+		return nil, fmt.Errorf("variable %s: invalid regex error=[%v]", e.Variable, err)
+	}
+	return re, nil
+}
+
+func (j *LoadJourney) Execute(ctx context.Context, id uint64) error {
+	start := time.Now()
+	vars := make(map[string]string)
+	if j.feeder != nil {
+		row, err := j.feeder.Next(ctx)
+		if err != nil {
+			return err
+		}
+		maps.Copy(vars, row)
+	}
+	for _, step := range j.steps {
+		category, message, err := j.executeStep(ctx, id, step, vars)
+		if err != nil {
+			return fmt.Errorf("step %s: %w", step.Name, err)
+		}
+		if category != "" {
+			j.RecordFailure(time.Since(start), step.Name+" "+category, message)
+			return nil
+		}
+	}
+	duration := time.Since(start)
+	j.Record(duration, true)
+	j.log.InfoLog.Printf("[JOURNEY] requestId=%d elapsed=%s", id, duration)
+	return nil
+}
+
+// executeStep executes a step and captures its extractions into vars. It returns the failure
+// category and message of a failed response, or the error of a request which could not be executed
+func (j *LoadJourney) executeStep(ctx context.Context, id uint64, step *journeyStep, vars map[string]string) (string, string, error) {
+	c := template.Context{ID: id, Vars: vars}
+	var body io.Reader
+	if step.body != nil {
+		body = strings.NewReader(step.body.Execute(c))
+	}
+	req, err := http.NewRequestWithContext(ctx, step.Method, step.url.render(c), body)
+	if err != nil {
+		return "", "", err
+	}
+	for k, t := range j.headers {
+		req.Header.Set(k, t.Execute(c))
+	}
+	for _, h := range step.headers {
+		req.Header.Set(h.key, h.value.Execute(c))
+	}
+
+	start := time.Now()
+	resp, err := j.client.Do(req)
+	if err != nil {
+		step.stats.RecordFailure(time.Since(start), load.Categorize(err), err.Error())
+		return "", "", err
+	}
+	content, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	duration := time.Since(start)
+	if err != nil {
+		step.stats.RecordFailure(duration, load.Categorize(err), err.Error())
+		return "", "", err
+	}
+
+	if !step.expects(resp.StatusCode) {
+		step.stats.RecordFailure(duration, statusCategory(resp.StatusCode), resp.Status)
+		j.log.ErrorLog.Printf("[JOURNEY] requestId=%d step=%s status=%d elapsed=%s", id, step.Name, resp.StatusCode, duration)
+		return statusCategory(resp.StatusCode), resp.Status, nil
+	}
+	for i, e := range step.Extract {
+		value, err := extractValue(e, step.regexes[i], resp, content)
+		if err != nil {
+			message := fmt.Sprintf("variable %s: %v", e.Variable, err)
+			step.stats.RecordFailure(duration, CategoryExtract, message)
+			j.log.ErrorLog.Printf("[JOURNEY] requestId=%d step=%s %s", id, step.Name, message)
+			return CategoryExtract, message, nil
+		}
+		vars[e.Variable] = value
+	}
+	step.stats.Record(duration, true)
+	j.log.InfoLog.Printf("[JOURNEY] requestId=%d step=%s status=%d elapsed=%s", id, step.Name, resp.StatusCode, duration)
+	return "", "", nil
+}
+
+// expects tells whether the status code is the expected one, any 2xx when none is configured
+func (s *journeyStep) expects(statusCode int) bool {
+	if s.ExpectedStatusCode == 0 {
+		return statusCode >= 200 && statusCode < 300
+	}
+	return statusCode == s.ExpectedStatusCode
+}
+
+func extractValue(e types.Extraction, re *regexp.Regexp, resp *http.Response, content []byte) (string, error) {
+	switch {
+	case e.JSONPath != "":
+		return extract.JSONPath(content, e.JSONPath)
+	case e.XPath != "":
+		return extract.XPath(content, e.XPath)
+	case e.Header != "":
+		value := resp.Header.Get(e.Header)
+		if value == "" {
+			return "", fmt.Errorf("no header %s", e.Header)
+		}
+		return value, nil
+	default:
+		m := re.FindSubmatch(content)
+		if m == nil {
+			return "", fmt.Errorf("no match for %s", e.Regex)
+		}
+		if len(m) > 1 {
+			return string(m[1]), nil
+		}
+		return string(m[0]), nil
+	}
+}
+
+func (j *LoadJourney) Success(response any) bool {
+	apiResponse := response.(*http.Response)
+	return apiResponse.StatusCode >= 200 && apiResponse.StatusCode < 300
+}
+
+// CalculateStats returns the stats of the whole journeys along with the stats of every step
+func (j *LoadJourney) CalculateStats() *load.Stats {
+	stats := j.BaseLoad.CalculateStats()
+	for _, step := range j.steps {
+		stats.Steps = append(stats.Steps, load.StepStats{Name: step.Name, Stats: step.stats.CalculateStats()})
+	}
+	return stats
+}
@@ -0,0 +1,276 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is a JSON schema. The validation keywords of the draft 2020-12 which apply to a single
+// document are supported: type, enum, const, properties, required, additionalProperties, items,
+// minItems, maxItems, minLength, maxLength, pattern, minimum, maximum, exclusiveMinimum,
+// exclusiveMaximum, allOf, anyOf, oneOf and not. References ($ref) are not
+type Schema struct {
+	root     any
+	patterns map[string]*regexp.Regexp
+}
+
+// Compile reads a JSON schema
+func Compile(b []byte) (*Schema, error) {
+	var root any
+	if err := json.Unmarshal(b, &root); err != nil {
+		return nil, fmt.Errorf("invalid json schema error=[%v]", err)
+	}
+	s := &Schema{root: root, patterns: make(map[string]*regexp.Regexp)}
+	if err := s.compile(root); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// compile checks the schema does not rely on unsupported keywords and compiles its patterns
+func (s *Schema) compile(node any) error {
+	switch n := node.(type) {
+	case map[string]any:
+		if _, ok := n["$ref"]; ok {
+			return fmt.Errorf("invalid json schema, $ref is not supported")
+		}
+		if pattern, ok := n["pattern"].(string); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("invalid json schema, pattern %q error=[%v]", pattern, err)
+			}
+			s.patterns[pattern] = re
+		}
+		for key, child := range n {
+			if key == "enum" || key == "const" {
+				continue
+			}
+			if err := s.compile(child); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, child := range n {
+			if err := s.compile(child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Validate validates a JSON document against the schema, the error lists every violation
+func (s *Schema) Validate(document []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("invalid json document error=[%v]", err)
+	}
+	if violations := s.validate(s.root, value, "$"); len(violations) > 0 {
+		return fmt.Errorf("%s", strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+func (s *Schema) validate(node any, value any, path string) []string {
+	schema, ok := node.(map[string]any)
+	if !ok {
+		if b, ok := node.(bool); ok && !b {
+			return []string{path + ": no value is allowed"}
+		}
+		return nil
+	}
+	var violations []string
+	fail := func(format string, args ...any) {
+		violations = append(violations, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if t, ok := schema["type"]; ok && !matchesType(t, value) {
+		fail("expected type %v, got %s", t, typeOf(value))
+		return violations
+	}
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			if equal(e, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("value not in enum %v", enum)
+		}
+	}
+	if c, ok := schema["const"]; ok && !equal(c, value) {
+		fail("expected %v", c)
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+		if required, ok := schema["required"].([]any); ok {
+			for _, r := range required {
+				if name, ok := r.(string); ok {
+					if _, ok := v[name]; !ok {
+						fail("missing required property %q", name)
+					}
+				}
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if property, ok := properties[key]; ok {
+				violations = append(violations, s.validate(property, v[key], path+"."+key)...)
+			} else if additional, ok := schema["additionalProperties"]; ok {
+				if b, ok := additional.(bool); ok && !b {
+					fail("additional property %q is not allowed", key)
+				} else {
+					violations = append(violations, s.validate(additional, v[key], path+"."+key)...)
+				}
+			}
+		}
+	case []any:
+		if items, ok := schema["items"]; ok {
+			for i, item := range v {
+				violations = append(violations, s.validate(items, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+		if n, ok := number(schema["minItems"]); ok && float64(len(v)) < n {
+			fail("expected at least %v items, got %d", n, len(v))
+		}
+		if n, ok := number(schema["maxItems"]); ok && float64(len(v)) > n {
+			fail("expected at most %v items, got %d", n, len(v))
+		}
+	case string:
+		length := float64(utf8.RuneCountInString(v))
+		if n, ok := number(schema["minLength"]); ok && length < n {
+			fail("expected at least %v characters, got %v", n, length)
+		}
+		if n, ok := number(schema["maxLength"]); ok && length > n {
+			fail("expected at most %v characters, got %v", n, length)
+		}
+		if pattern, ok := schema["pattern"].(string); ok && !s.patterns[pattern].MatchString(v) {
+			fail("%q does not match %q", v, pattern)
+		}
+	case json.Number:
+		f, _ := v.Float64()
+		if n, ok := number(schema["minimum"]); ok && f < n {
+			fail("expected >= %v, got %v", n, v)
+		}
+		if n, ok := number(schema["maximum"]); ok && f > n {
+			fail("expected <= %v, got %v", n, v)
+		}
+		if n, ok := number(schema["exclusiveMinimum"]); ok && f <= n {
+			fail("expected > %v, got %v", n, v)
+		}
+		if n, ok := number(schema["exclusiveMaximum"]); ok && f >= n {
+			fail("expected < %v, got %v", n, v)
+		}
+	}
+
+	if all, ok := schema["allOf"].([]any); ok {
+		for _, sub := range all {
+			violations = append(violations, s.validate(sub, value, path)...)
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		matched := 0
+		for _, sub := range anyOf {
+			if len(s.validate(sub, value, path)) == 0 {
+				matched++
+			}
+		}
+		if matched == 0 {
+			fail("matches none of anyOf")
+		}
+	}
+	if oneOf, ok := schema["oneOf"].([]any); ok {
+		matched := 0
+		for _, sub := range oneOf {
+			if len(s.validate(sub, value, path)) == 0 {
+				matched++
+			}
+		}
+		if matched != 1 {
+			fail("matches %d of oneOf, expected exactly 1", matched)
+		}
+	}
+	if not, ok := schema["not"]; ok && len(s.validate(not, value, path)) == 0 {
+		fail("matches the not schema")
+	}
+	return violations
+}
+
+func matchesType(t any, value any) bool {
+	switch t := t.(type) {
+	case string:
+		return t == typeOf(value) || (t == "number" && typeOf(value) == "integer")
+	case []any:
+		for _, one := range t {
+			if matchesType(one, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func typeOf(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		if f, err := v.Float64(); err == nil && f == float64(int64(f)) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// number reads a numeric keyword of the schema
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// equal compares a value of the schema with a value of the document
+func equal(expected, actual any) bool {
+	if n, ok := actual.(json.Number); ok {
+		e, ok := number(expected)
+		f, _ := n.Float64()
+		return ok && e == f
+	}
+	a, errA := json.Marshal(expected)
+	b, errB := json.Marshal(actual)
+	return errA == nil && errB == nil && bytes.Equal(a, b)
+}
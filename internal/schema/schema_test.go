@@ -0,0 +1,134 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+const orderSchema = `{
+  "type": "object",
+  "required": ["id", "status", "items"],
+  "additionalProperties": false,
+  "properties": {
+    "id": {"type": "string", "pattern": "^ORD-[0-9]+$"},
+    "status": {"enum": ["NEW", "PAID", "SHIPPED"]},
+    "total": {"type": "number", "minimum": 0, "exclusiveMaximum": 10000},
+    "express": {"type": "boolean"},
+    "note": {"type": ["string", "null"], "maxLength": 10},
+    "items": {
+      "type": "array",
+      "minItems": 1,
+      "maxItems": 3,
+      "items": {
+        "type": "object",
+        "required": ["sku", "quantity"],
+        "properties": {
+          "sku": {"type": "string", "minLength": 3},
+          "quantity": {"type": "integer", "minimum": 1}
+        }
+      }
+    }
+  }
+}`
+
+func TestValidate(t *testing.T) {
+	s, err := Compile([]byte(orderSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		document string
+		errors   []string // parts of the expected violations, none when the document is valid
+	}{
+		{
+			name:     "valid",
+			document: `{"id": "ORD-1", "status": "NEW", "total": 12.5, "note": null, "items": [{"sku": "BOOK", "quantity": 2}]}`,
+		},
+		{
+			name:     "missing property",
+			document: `{"id": "ORD-1", "items": [{"sku": "BOOK", "quantity": 1}]}`,
+			errors:   []string{`$: missing required property "status"`},
+		},
+		{
+			name:     "additional property",
+			document: `{"id": "ORD-1", "status": "NEW", "items": [{"sku": "BOOK", "quantity": 1}], "extra": 1}`,
+			errors:   []string{`additional property "extra"`},
+		},
+		{
+			name:     "wrong types",
+			document: `{"id": 1, "status": "NEW", "express": "yes", "items": [{"sku": "BOOK", "quantity": 1.5}]}`,
+			errors:   []string{"$.id: expected type string", "$.express: expected type boolean", "$.items[0].quantity: expected type integer"},
+		},
+		{
+			name:     "constraints",
+			document: `{"id": "X-1", "status": "LOST", "total": 10000, "note": "far too long", "items": []}`,
+			errors:   []string{"$.id", "$.status: value not in enum", "$.total", "$.note", "$.items"},
+		},
+		{
+			name:     "not json",
+			document: `{"id": `,
+			errors:   []string{"invalid json document"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Validate([]byte(tt.document))
+			if len(tt.errors) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want no error", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() = nil, want %v", tt.errors)
+			}
+			for _, e := range tt.errors {
+				if !strings.Contains(err.Error(), e) {
+					t.Errorf("Validate() = %v, want it to contain %q", err, e)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateCombinators(t *testing.T) {
+	s, err := Compile([]byte(`{
+  "anyOf": [{"type": "string"}, {"type": "integer"}],
+  "not": {"const": 0},
+  "oneOf": [{"type": "integer", "minimum": 10}, {"type": "string"}, {"type": "integer", "maximum": 5}]
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := []string{`"text"`, `12`, `3`}
+	invalid := []string{`true`, `0`, `7`, `1.5`}
+	for _, d := range valid {
+		if err := s.Validate([]byte(d)); err != nil {
+			t.Errorf("Validate(%s) = %v, want no error", d, err)
+		}
+	}
+	for _, d := range invalid {
+		if err := s.Validate([]byte(d)); err == nil {
+			t.Errorf("Validate(%s) = nil, want an error", d)
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		schema  string
+		wantErr bool
+	}{
+		{`{"type": "object"}`, false},
+		{`true`, false},
+		{`{"properties": {"a": {"$ref": "#/defs/a"}}}`, true},
+		{`{"pattern": "("}`, true},
+		{`{"type": `, true},
+	}
+	for _, tt := range tests {
+		if _, err := Compile([]byte(tt.schema)); (err != nil) != tt.wantErr {
+			t.Errorf("Compile(%s) error = %v, wantErr %v", tt.schema, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package secret
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	Mask = "******" // replaces the secrets in the logs and the reports
+	// MinLength is the length of the shortest secret redacted, masking shorter values would mask
+	// any text holding their characters
+	MinLength = 4
+)
+
+var (
+	mu       sync.RWMutex
+	secrets  = make(map[string]bool)
+	replacer = strings.NewReplacer()
+)
+
+// sensitiveKeys are the parts of the names of the config keys and headers holding secrets
+var sensitiveKeys = []string{"secret", "password", "passwd", "token", "apikey", "api-key", "api_key",
+	"authorization", "credential", "privatekey", "private_key"}
+
+// IsSensitive tells if the config key or the header name holds a secret
+func IsSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range sensitiveKeys {
+		if strings.Contains(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add registers a secret to be redacted, it returns false when the secret is shorter than
+// MinLength and is not redacted
+func Add(value string) bool {
+	if len(value) < MinLength {
+		return false
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if secrets[value] {
+		return true
+	}
+	secrets[value] = true
+	values := make([]string, 0, len(secrets))
+	for s := range secrets {
+		values = append(values, s)
+	}
+	// the longest secret wins over a secret it contains
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	oldnew := make([]string, 0, 2*len(values))
+	for _, s := range values {
+		oldnew = append(oldnew, s, Mask)
+	}
+	replacer = strings.NewReplacer(oldnew...)
+	return true
+}
+
+// Redact replaces the registered secrets of s with Mask
+func Redact(s string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	if len(secrets) == 0 {
+		return s
+	}
+	return replacer.Replace(s)
+}
+
+// Writer redacts the secrets of what is written to w. A log.Logger writes every line at once,
+// so no secret is split between two writes
+func Writer(w io.Writer) io.Writer {
+	return redactWriter{w: w}
+}
+
+type redactWriter struct {
+	w io.Writer
+}
+
+func (r redactWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(r.w, Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
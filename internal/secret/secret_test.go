@@ -0,0 +1,60 @@
+package secret
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestIsSensitive(t *testing.T) {
+	tests := map[string]bool{
+		"clientSecret":  true,
+		"password":      true,
+		"Authorization": true,
+		"X-Api-Key":     true,
+		"accessToken":   true,
+		"url":           false,
+		"Content-Type":  false,
+		"ratePerSec":    false,
+	}
+	for key, want := range tests {
+		if got := IsSensitive(key); got != want {
+			t.Errorf("IsSensitive(%s) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+	if Add("abc") {
+		t.Errorf("Add(abc) = true, want a secret shorter than %d characters refused", MinLength)
+	}
+	for _, s := range []string{"token-1234", "token-1234-long", "p4ss"} {
+		if !Add(s) {
+			t.Errorf("Add(%s) = false, want true", s)
+		}
+	}
+	Add("p4ss") // added twice
+	tests := map[string]string{
+		"no secret abc":              "no secret abc",
+		"Bearer token-1234":          "Bearer " + Mask,
+		"id=token-1234-long&pw=p4ss": "id=" + Mask + "&pw=" + Mask,
+		"token-1234token-1234":       Mask + Mask,
+	}
+	for s, want := range tests {
+		if got := Redact(s); got != want {
+			t.Errorf("Redact(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
+
+func TestWriter(t *testing.T) {
+	Add("writer-secret")
+	var b bytes.Buffer
+	line := "logged writer-secret\n"
+	n, err := Writer(&b).Write([]byte(line))
+	if err != nil || n != len(line) {
+		t.Fatalf("Write() = %d, %v, want %d, nil", n, err, len(line))
+	}
+	if want := "logged " + Mask + "\n"; b.String() != want {
+		t.Errorf("Write() wrote %q, want %q", b.String(), want)
+	}
+}
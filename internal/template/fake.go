@@ -0,0 +1,22 @@
+package template
+
+// values the fake names, emails and addresses are picked from
+var (
+	firstNames = []string{
+		"James", "Mary", "Robert", "Patricia", "John", "Jennifer", "Michael", "Linda", "David", "Elizabeth",
+		"William", "Barbara", "Richard", "Susan", "Joseph", "Jessica", "Thomas", "Sarah", "Carlos", "Maria",
+		"Wei", "Aiko", "Ravi", "Priya", "Omar", "Fatima", "Lucas", "Sofia", "Noah", "Emma",
+	}
+	lastNames = []string{
+		"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez",
+		"Hernandez", "Lopez", "Wilson", "Anderson", "Thomas", "Taylor", "Moore", "Jackson", "Martin", "Lee",
+		"Chen", "Tanaka", "Patel", "Sharma", "Khan", "Silva", "Mueller", "Rossi", "Dubois", "Kowalski",
+	}
+	domains     = []string{"example.com", "example.org", "example.net", "mail.test", "inbox.test"}
+	streets     = []string{"Main", "Oak", "Pine", "Maple", "Cedar", "Elm", "Washington", "Lake", "Hill", "Park"}
+	streetTypes = []string{"Street", "Avenue", "Road", "Boulevard", "Lane", "Drive", "Court", "Way"}
+	cities      = []string{
+		"Springfield", "Riverside", "Franklin", "Greenville", "Bristol", "Clinton", "Fairview", "Salem",
+		"Madison", "Georgetown", "Arlington", "Ashland", "Dover", "Oxford", "Jackson",
+	}
+)
@@ -0,0 +1,227 @@
+package template
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// generator compiles the arguments of a call into the function generating its values
+type generator func(args []string) (func(c *Context) string, error)
+
+// generators by name:
+//
+//	uuid                          a random UUID
+//	randomInt min max             an integer from min to max included
+//	randomFloat min max [digits]  a number from min to max with digits decimals, 2 by default
+//	randomString n                n letters
+//	randomAlphanumeric n          n letters and digits
+//	timestamp [format]            the current time, see formats, rfc3339ms by default
+//	date offset [format]          the current time moved by offset such as -7d, 36h or +1d12h, date by default
+//	sequence [start] [step]       start + (id-1)*step, the id of the request by default
+//	choice a b ...                one of the arguments
+//	firstName, lastName, name     fake names
+//	email                         a fake email address
+//	address, city, zipCode        a fake address
+var generators = map[string]generator{
+	"uuid": noArgs(func(c *Context) string { return uuid.NewString() }),
+	"randomInt": func(args []string) (func(c *Context) string, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("expected min and max")
+		}
+		min, err := parseInt(args[0])
+		if err != nil {
+			return nil, err
+		}
+		max, err := parseInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		if min > max {
+			return nil, fmt.Errorf("min %d above max %d", min, max)
+		}
+		if span := max - min; span < 0 || span == math.MaxInt64 {
+			return nil, fmt.Errorf("range from %d to %d too wide", min, max)
+		}
+		return func(c *Context) string {
+			return strconv.FormatInt(min+rand.Int64N(max-min+1), 10)
+		}, nil
+	},
+	"randomFloat": func(args []string) (func(c *Context) string, error) {
+		if len(args) != 2 && len(args) != 3 {
+			return nil, fmt.Errorf("expected min, max and optionally digits")
+		}
+		min, err := parseFloat(args[0])
+		if err != nil {
+			return nil, err
+		}
+		max, err := parseFloat(args[1])
+		if err != nil {
+			return nil, err
+		}
+		if min > max {
+			return nil, fmt.Errorf("min %v above max %v", min, max)
+		}
+		if math.IsInf(max-min, 0) {
+			return nil, fmt.Errorf("range from %v to %v too wide", min, max)
+		}
+		digits := int64(2)
+		if len(args) == 3 {
+			if digits, err = parseInt(args[2]); err != nil || digits < 0 {
+				return nil, fmt.Errorf("invalid digits %q", args[2])
+			}
+		}
+		return func(c *Context) string {
+			return strconv.FormatFloat(min+rand.Float64()*(max-min), 'f', int(digits), 64)
+		}, nil
+	},
+	"randomString":       randomText("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"),
+	"randomAlphanumeric": randomText("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"),
+	"timestamp": func(args []string) (func(c *Context) string, error) {
+		if len(args) > 1 {
+			return nil, fmt.Errorf("expected at most a format")
+		}
+		format := formatter(args, "rfc3339ms")
+		return func(c *Context) string { return format(time.Now()) }, nil
+	},
+	"date": func(args []string) (func(c *Context) string, error) {
+		if len(args) != 1 && len(args) != 2 {
+			return nil, fmt.Errorf("expected an offset and optionally a format")
+		}
+		offset, err := parseOffset(args[0])
+		if err != nil {
+			return nil, err
+		}
+		format := formatter(args[1:], "date")
+		return func(c *Context) string { return format(time.Now().Add(offset)) }, nil
+	},
+	"sequence": func(args []string) (func(c *Context) string, error) {
+		if len(args) > 2 {
+			return nil, fmt.Errorf("expected at most a start and a step")
+		}
+		start, step := int64(1), int64(1)
+		var err error
+		if len(args) > 0 {
+			if start, err = parseInt(args[0]); err != nil {
+				return nil, err
+			}
+		}
+		if len(args) > 1 {
+			if step, err = parseInt(args[1]); err != nil {
+				return nil, err
+			}
+		}
+		return func(c *Context) string {
+			return strconv.FormatInt(start+int64(c.ID-1)*step, 10)
+		}, nil
+	},
+	"choice": func(args []string) (func(c *Context) string, error) {
+		if len(args) == 0 {
+			return nil, fmt.Errorf("expected the values to choose from")
+		}
+		return func(c *Context) string { return pick(args) }, nil
+	},
+	"firstName": noArgs(func(c *Context) string { return pick(firstNames) }),
+	"lastName":  noArgs(func(c *Context) string { return pick(lastNames) }),
+	"name":      noArgs(func(c *Context) string { return pick(firstNames) + " " + pick(lastNames) }),
+	"email": noArgs(func(c *Context) string {
+		return fmt.Sprintf("%s.%s%d@%s", strings.ToLower(pick(firstNames)), strings.ToLower(pick(lastNames)),
+			rand.IntN(1000), pick(domains))
+	}),
+	"address": noArgs(func(c *Context) string {
+		return fmt.Sprintf("%d %s %s", 1+rand.IntN(9999), pick(streets), pick(streetTypes))
+	}),
+	"city":    noArgs(func(c *Context) string { return pick(cities) }),
+	"zipCode": noArgs(func(c *Context) string { return fmt.Sprintf("%05d", rand.IntN(100000)) }),
+}
+
+func noArgs(generate func(c *Context) string) generator {
+	return func(args []string) (func(c *Context) string, error) {
+		if len(args) > 0 {
+			return nil, fmt.Errorf("expected no argument")
+		}
+		return generate, nil
+	}
+}
+
+func randomText(alphabet string) generator {
+	return func(args []string) (func(c *Context) string, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("expected a length")
+		}
+		n, err := parseInt(args[0])
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid length %q", args[0])
+		}
+		return func(c *Context) string {
+			b := make([]byte, n)
+			for i := range b {
+				b[i] = alphabet[rand.IntN(len(alphabet))]
+			}
+			return string(b)
+		}, nil
+	}
+}
+
+func pick(values []string) string {
+	return values[rand.IntN(len(values))]
+}
+
+// formats of the timestamps and dates by name, any other format is a Go layout such as "2006-01-02 15:04"
+var formats = map[string]func(t time.Time) string{
+	"rfc3339":    func(t time.Time) string { return t.Format(time.RFC3339) },
+	"rfc3339ms":  func(t time.Time) string { return t.Format("2006-01-02T15:04:05.000Z07:00") },
+	"rfc3339ns":  func(t time.Time) string { return t.Format(time.RFC3339Nano) },
+	"rfc1123":    func(t time.Time) string { return t.UTC().Format(time.RFC1123) },
+	"date":       func(t time.Time) string { return t.Format(time.DateOnly) },
+	"datetime":   func(t time.Time) string { return t.Format(time.DateTime) },
+	"unix":       func(t time.Time) string { return strconv.FormatInt(t.Unix(), 10) },
+	"unixMillis": func(t time.Time) string { return strconv.FormatInt(t.UnixMilli(), 10) },
+	"unixNanos":  func(t time.Time) string { return strconv.FormatInt(t.UnixNano(), 10) },
+}
+
+func formatter(args []string, fallback string) func(t time.Time) string {
+	name := fallback
+	if len(args) > 0 {
+		name = args[0]
+	}
+	if format, ok := formats[name]; ok {
+		return format
+	}
+	return func(t time.Time) string { return t.Format(name) }
+}
+
+var offsetPattern = regexp.MustCompile(`^([+-]?)(?:(\d+)d)?(.*)$`)
+
+// parseOffset reads a duration which can start with a number of days, e.g. -7d, 36h or +1d12h
+func parseOffset(s string) (time.Duration, error) {
+	m := offsetPattern.FindStringSubmatch(s)
+	if m == nil || (m[2] == "" && m[3] == "") {
+		return 0, fmt.Errorf("invalid offset %q", s)
+	}
+	var offset time.Duration
+	if m[2] != "" {
+		days, err := strconv.Atoi(m[2])
+		if err != nil {
+			return 0, fmt.Errorf("invalid offset %q", s)
+		}
+		offset = time.Duration(days) * 24 * time.Hour
+	}
+	if m[3] != "" {
+		d, err := time.ParseDuration(m[3])
+		if err != nil || d < 0 {
+			return 0, fmt.Errorf("invalid offset %q", s)
+		}
+		offset += d
+	}
+	if m[1] == "-" {
+		offset = -offset
+	}
+	return offset, nil
+}
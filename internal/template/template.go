@@ -0,0 +1,153 @@
+package template
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Context is what a template is executed with
+type Context struct {
+	ID   uint64            // id of the request, the base of the sequences
+	Vars map[string]string // variables such as the values captured by the steps of a journey
+}
+
+// Template is a text with {{name}} references to variables and {{generator args}} calls to
+// generators, e.g. `{"id": "{{uuid}}", "qty": {{randomInt 1 10}}}`. The arguments are separated
+// by spaces, an argument holding spaces is double quoted. A reference to a variable which is not
+// set is kept as it is, a reference with the name of a generator which takes no arguments calls
+// it when no variable of that name is set
+type Template struct {
+	parts []part
+}
+
+// part is a literal text, a reference to a variable or a call to a generator
+type part struct {
+	text     string
+	variable string
+	generate func(c *Context) string
+}
+
+// Parse reads a template, it fails on unknown generators and invalid arguments
+func Parse(s string) (*Template, error) {
+	t := &Template{}
+	rest := s
+	for rest != "" {
+		open := strings.Index(rest, "{{")
+		if open < 0 {
+			t.parts = append(t.parts, part{text: rest})
+			break
+		}
+		if open > 0 {
+			t.parts = append(t.parts, part{text: rest[:open]})
+		}
+		end := strings.Index(rest[open:], "}}")
+		if end < 0 {
+			return nil, fmt.Errorf("invalid template, {{ at %d without }}", len(s)-len(rest)+open)
+		}
+		action := rest[open+2 : open+end]
+		rest = rest[open+end+2:]
+		p, err := parseAction(action)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template {{%s}}: %v", action, err)
+		}
+		t.parts = append(t.parts, p)
+	}
+	return t, nil
+}
+
+func parseAction(action string) (part, error) {
+	fields, err := splitArgs(action)
+	if err != nil {
+		return part{}, err
+	}
+	if len(fields) == 0 {
+		return part{}, fmt.Errorf("empty action")
+	}
+	name, args := fields[0], fields[1:]
+	g, ok := generators[name]
+	if !ok {
+		if len(args) > 0 {
+			return part{}, fmt.Errorf("unknown generator %s", name)
+		}
+		return part{variable: name}, nil
+	}
+	generate, err := g(args)
+	if err != nil {
+		if len(args) == 0 {
+			// a generator which needs arguments, {{name}} can only be a variable
+			return part{variable: name}, nil
+		}
+		return part{}, fmt.Errorf("%s: %v", name, err)
+	}
+	p := part{generate: generate}
+	if len(args) == 0 {
+		p.variable = name // a variable with the same name wins over the generator
+	}
+	return p, nil
+}
+
+// splitArgs splits an action on spaces, keeping the double quoted arguments whole
+func splitArgs(action string) ([]string, error) {
+	var fields []string
+	rest := strings.TrimSpace(action)
+	for rest != "" {
+		if rest[0] == '"' {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("missing closing quote")
+			}
+			fields = append(fields, rest[1:end+1])
+			rest = strings.TrimSpace(rest[end+2:])
+			continue
+		}
+		end := strings.IndexAny(rest, " \t")
+		if end < 0 {
+			end = len(rest)
+		}
+		fields = append(fields, rest[:end])
+		rest = strings.TrimSpace(rest[end:])
+	}
+	return fields, nil
+}
+
+// Execute renders the template
+func (t *Template) Execute(c Context) string {
+	if len(t.parts) == 1 && t.parts[0].variable == "" && t.parts[0].generate == nil {
+		return t.parts[0].text
+	}
+	var b strings.Builder
+	for _, p := range t.parts {
+		switch {
+		case p.variable != "":
+			if value, ok := c.Vars[p.variable]; ok {
+				b.WriteString(value)
+			} else if p.generate != nil {
+				b.WriteString(p.generate(&c))
+			} else {
+				b.WriteString("{{" + p.variable + "}}")
+			}
+		case p.generate != nil:
+			b.WriteString(p.generate(&c))
+		default:
+			b.WriteString(p.text)
+		}
+	}
+	return b.String()
+}
+
+func parseInt(s string) (int64, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer %q", s)
+	}
+	return n, nil
+}
+
+func parseFloat(s string) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	return f, nil
+}
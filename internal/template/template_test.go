@@ -0,0 +1,156 @@
+package template
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecute(t *testing.T) {
+	vars := map[string]string{"orderId": "ORD-1", "date": "2024-01-31", "uuid": "fixed"}
+	tests := []struct {
+		template string
+		id       uint64
+		want     string
+	}{
+		{template: "no action", want: "no action"},
+		{template: "", want: ""},
+		{template: `{"id": "{{orderId}}"}`, want: `{"id": "ORD-1"}`},
+		{template: "{{ orderId }}/{{orderId}}", want: "ORD-1/ORD-1"},
+		{template: "{{missing}}", want: "{{missing}}"},
+		{template: "{{date}}", want: "2024-01-31"},
+		{template: "{{uuid}}", want: "fixed"},
+		{template: "{{sequence}}", id: 7, want: "7"},
+		{template: "n{{sequence 100 10}}", id: 3, want: "n120"},
+		{template: "{{sequence -5 -1}}", id: 1, want: "-5"},
+		{template: `{{choice "only one"}}`, want: "only one"},
+		{template: "{{randomInt 4 4}}", want: "4"},
+		{template: "{{randomFloat 1.5 1.5 3}}", want: "1.500"},
+	}
+	for _, tt := range tests {
+		tmpl, err := Parse(tt.template)
+		if err != nil {
+			t.Errorf("Parse(%s) error = %v", tt.template, err)
+			continue
+		}
+		if got := tmpl.Execute(Context{ID: tt.id, Vars: vars}); got != tt.want {
+			t.Errorf("Execute(%s) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestGeneratorWithoutVariable(t *testing.T) {
+	// a generator taking no arguments is called when no variable of its name is set
+	tmpl, err := Parse("{{uuid}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tmpl.Execute(Context{}); len(got) != 36 {
+		t.Errorf("Execute({{uuid}}) = %q, want a uuid", got)
+	}
+	// a generator needing arguments is only a variable
+	tmpl, err = Parse("{{date}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tmpl.Execute(Context{}); got != "{{date}}" {
+		t.Errorf("Execute({{date}}) = %q, want it kept as it is", got)
+	}
+}
+
+func TestGenerators(t *testing.T) {
+	tests := []struct {
+		template string
+		valid    func(s string) bool
+	}{
+		{"{{randomInt -3 3}}", func(s string) bool {
+			n, err := strconv.Atoi(s)
+			return err == nil && n >= -3 && n <= 3
+		}},
+		{"{{randomFloat 0 1}}", func(s string) bool {
+			f, err := strconv.ParseFloat(s, 64)
+			return err == nil && f >= 0 && f <= 1 && len(s) == 4
+		}},
+		{"{{randomString 8}}", func(s string) bool { return len(s) == 8 }},
+		{"{{randomAlphanumeric 12}}", func(s string) bool { return len(s) == 12 }},
+		{"{{choice a b c}}", func(s string) bool { return slices.Contains([]string{"a", "b", "c"}, s) }},
+		{"{{date -1d}}", func(s string) bool { return s == time.Now().AddDate(0, 0, -1).Format(time.DateOnly) }},
+		{`{{date +2h "2006-01-02 15"}}`, func(s string) bool { return s == time.Now().Add(2*time.Hour).Format("2006-01-02 15") }},
+		{"{{timestamp unixMillis}}", func(s string) bool {
+			ms, err := strconv.ParseInt(s, 10, 64)
+			return err == nil && time.Since(time.UnixMilli(ms)).Abs() < time.Minute
+		}},
+		{"{{email}}", func(s string) bool { return strings.Contains(s, "@") }},
+		{"{{zipCode}}", func(s string) bool { return len(s) == 5 }},
+	}
+	for _, tt := range tests {
+		tmpl, err := Parse(tt.template)
+		if err != nil {
+			t.Errorf("Parse(%s) error = %v", tt.template, err)
+			continue
+		}
+		for range 20 {
+			if got := tmpl.Execute(Context{ID: 1}); !tt.valid(got) {
+				t.Errorf("Execute(%s) = %q", tt.template, got)
+				break
+			}
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"{{orderId",
+		"{{}}",
+		"{{unknown 1}}",
+		`{{choice "a}}`,
+		"{{uuid 4}}",
+		"{{randomInt 1}}",
+		"{{randomInt 10 1}}",
+		"{{randomInt one 10}}",
+		"{{randomInt -9223372036854775808 9223372036854775807}}",
+		"{{randomInt 0 9223372036854775807}}",
+		"{{randomFloat -1e308 1e308}}",
+		"{{randomFloat 0 1 -1}}",
+		"{{randomString 0}}",
+		"{{date yesterday}}",
+		"{{date 1d format extra}}",
+		"{{sequence 1 2 3}}",
+		"{{timestamp unix date}}",
+	}
+	for _, s := range tests {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("Parse(%s) = nil error, want one", s)
+		}
+	}
+}
+
+func TestParseOffset(t *testing.T) {
+	tests := []struct {
+		offset  string
+		want    time.Duration
+		wantErr bool
+	}{
+		{offset: "7d", want: 7 * 24 * time.Hour},
+		{offset: "-7d", want: -7 * 24 * time.Hour},
+		{offset: "+1d12h", want: 36 * time.Hour},
+		{offset: "36h", want: 36 * time.Hour},
+		{offset: "-90m", want: -90 * time.Minute},
+		{offset: "", wantErr: true},
+		{offset: "-", wantErr: true},
+		{offset: "1w", wantErr: true},
+		{offset: "1d-2h", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseOffset(tt.offset)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseOffset(%q) error = %v, wantErr %v", tt.offset, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseOffset(%q) = %s, want %s", tt.offset, got, tt.want)
+		}
+	}
+}
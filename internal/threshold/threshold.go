@@ -0,0 +1,175 @@
+package threshold
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Names of the metrics a threshold can refer to. Latencies are in milliseconds, rates in percent
+const (
+	Total       = "total"
+	Success     = "success"
+	Fail        = "fail"
+	Failed      = "failed" // requests whose execution returned an error
+	ErrorRate   = "errorRate"
+	AchievedRps = "achievedRps"
+	Target      = "target" // average target rate of the profile, 0 in closed mode
+	DurationS   = "duration"
+	Min         = "min"
+	Avg         = "avg"
+	P50         = "p50"
+	P90         = "p90"
+	P95         = "p95"
+	P99         = "p99"
+	Max         = "max"
+	ResponseMin = "responseMin"
+	ResponseAvg = "responseAvg"
+	ResponseP50 = "responseP50"
+	ResponseP90 = "responseP90"
+	ResponseP95 = "responseP95"
+	ResponseP99 = "responseP99"
+	ResponseMax = "responseMax"
+)
+
+var metrics = map[string]bool{
+	Total: true, Success: true, Fail: true, Failed: true, ErrorRate: true, AchievedRps: true, Target: true,
+	DurationS: true, Min: true, Avg: true, P50: true, P90: true, P95: true, P99: true, Max: true,
+	ResponseMin: true, ResponseAvg: true, ResponseP50: true, ResponseP90: true, ResponseP95: true,
+	ResponseP99: true, ResponseMax: true,
+}
+
+// Threshold is a condition on a metric such as `p95 < 300ms`, `errorRate < 1%` or `achievedRps >= 0.95 * target`
+type Threshold struct {
+	Expression string
+	metric     string
+	op         string
+	factors    []factor // the right hand side is the product of its factors
+}
+
+// factor is either a constant or a metric
+type factor struct {
+	value  float64
+	metric string
+}
+
+// Result is the outcome of a threshold evaluated against the metrics of a run
+type Result struct {
+	Expression string  `json:"expression"`
+	Actual     float64 `json:"actual"`
+	Expected   float64 `json:"expected"`
+	Passed     bool    `json:"passed"`
+}
+
+var (
+	expressionRegex = regexp.MustCompile(`^\s*([A-Za-z][A-Za-z0-9]*)\s*(<=|>=|==|!=|<|>)\s*(.+?)\s*$`)
+	numberRegex     = regexp.MustCompile(`^([0-9]*\.?[0-9]+)\s*(ns|us|µs|ms|s|m|h|%)?$`)
+	metricRegex     = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+)
+
+// milliseconds in each duration unit
+var units = map[string]float64{
+	"ns": 1e-6,
+	"us": 1e-3,
+	"µs": 1e-3,
+	"ms": 1,
+	"s":  1e3,
+	"m":  60e3,
+	"h":  3600e3,
+}
+
+// Parse parses a threshold expression. Durations are converted to milliseconds and
+// percentages are kept as numbers of percent, a plain number is compared as is
+func Parse(expression string) (Threshold, error) {
+	m := expressionRegex.FindStringSubmatch(expression)
+	if m == nil {
+		return Threshold{}, fmt.Errorf("invalid threshold %q, expected <metric> <operator> <value>", expression)
+	}
+	t := Threshold{Expression: strings.TrimSpace(expression), metric: m[1], op: m[2]}
+	if !metrics[t.metric] {
+		return Threshold{}, fmt.Errorf("invalid threshold %q, unknown metric %q", expression, t.metric)
+	}
+	for _, term := range strings.Split(m[3], "*") {
+		term = strings.TrimSpace(term)
+		if n := numberRegex.FindStringSubmatch(term); n != nil {
+			value, err := strconv.ParseFloat(n[1], 64)
+			if err != nil {
+				return Threshold{}, fmt.Errorf("invalid threshold %q error=[%v]", expression, err)
+			}
+			if unit, ok := units[n[2]]; ok {
+				value *= unit
+			}
+			t.factors = append(t.factors, factor{value: value})
+		} else if metricRegex.MatchString(term) && metrics[term] {
+			t.factors = append(t.factors, factor{metric: term})
+		} else {
+			return Threshold{}, fmt.Errorf("invalid threshold %q, cannot read value %q", expression, term)
+		}
+	}
+	return t, nil
+}
+
+// ParseAll parses every expression, failing on the first invalid one
+func ParseAll(expressions []string) ([]Threshold, error) {
+	thresholds := make([]Threshold, 0, len(expressions))
+	for _, expression := range expressions {
+		t, err := Parse(expression)
+		if err != nil {
+			return nil, err
+		}
+		thresholds = append(thresholds, t)
+	}
+	return thresholds, nil
+}
+
+// Evaluate checks the threshold against the metrics, missing metrics count as 0
+func (t Threshold) Evaluate(values map[string]float64) Result {
+	expected := 1.0
+	for _, f := range t.factors {
+		if f.metric != "" {
+			expected *= values[f.metric]
+		} else {
+			expected *= f.value
+		}
+	}
+	actual := values[t.metric]
+	var passed bool
+	switch t.op {
+	case "<":
+		passed = actual < expected
+	case "<=":
+		passed = actual <= expected
+	case ">":
+		passed = actual > expected
+	case ">=":
+		passed = actual >= expected
+	case "==":
+		passed = actual == expected
+	case "!=":
+		passed = actual != expected
+	}
+	return Result{Expression: t.Expression, Actual: actual, Expected: expected, Passed: passed}
+}
+
+// EvaluateAll parses and evaluates the expressions, invalid ones are reported as failed
+func EvaluateAll(expressions []string, values map[string]float64) []Result {
+	results := make([]Result, 0, len(expressions))
+	for _, expression := range expressions {
+		t, err := Parse(expression)
+		if err != nil {
+			results = append(results, Result{Expression: err.Error()})
+			continue
+		}
+		results = append(results, t.Evaluate(values))
+	}
+	return results
+}
+
+func (r Result) String() string {
+	status := "PASSED"
+	if !r.Passed {
+		status = "FAILED"
+	}
+	return fmt.Sprintf("%s %s (actual=%.3f expected=%.3f)", status, r.Expression, r.Actual, r.Expected)
+}
@@ -0,0 +1,107 @@
+package threshold
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expression string
+		metric     string
+		op         string
+		expected   float64 // evaluated with target=100
+		wantErr    bool
+	}{
+		{expression: "p95 < 300ms", metric: P95, op: "<", expected: 300},
+		{expression: "p99<=1.5s", metric: P99, op: "<=", expected: 1500},
+		{expression: "  max < 2m ", metric: Max, op: "<", expected: 120000},
+		{expression: "avg < 500us", metric: Avg, op: "<", expected: 0.5},
+		{expression: "avg < 500µs", metric: Avg, op: "<", expected: 0.5},
+		{expression: "min > 100000ns", metric: Min, op: ">", expected: 0.1},
+		{expression: "responseP99 < 1h", metric: ResponseP99, op: "<", expected: 3600000},
+		{expression: "errorRate < 1%", metric: ErrorRate, op: "<", expected: 1},
+		{expression: "fail == 0", metric: Fail, op: "==", expected: 0},
+		{expression: "failed != 3", metric: Failed, op: "!=", expected: 3},
+		{expression: "achievedRps >= 0.95 * target", metric: AchievedRps, op: ">=", expected: 95},
+		{expression: "total > target * 2 * 0.5", metric: Total, op: ">", expected: 100},
+		{expression: "p95", wantErr: true},
+		{expression: "p95 < ", wantErr: true},
+		{expression: "latency < 300ms", wantErr: true},
+		{expression: "p95 < 300 parsecs", wantErr: true},
+		{expression: "p95 < unknown", wantErr: true},
+		{expression: "p95 < -1ms", wantErr: true},
+		{expression: "p95 =< 3ms", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			th, err := Parse(tt.expression)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.expression, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if th.metric != tt.metric || th.op != tt.op {
+				t.Errorf("Parse(%q) = %s %s, want %s %s", tt.expression, th.metric, th.op, tt.metric, tt.op)
+			}
+			if r := th.Evaluate(map[string]float64{Target: 100}); math.Abs(r.Expected-tt.expected) > 1e-9 {
+				t.Errorf("Parse(%q) expects %v, want %v", tt.expression, r.Expected, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	values := map[string]float64{P95: 250, ErrorRate: 2, AchievedRps: 96, Target: 100}
+	tests := []struct {
+		expression string
+		passed     bool
+		actual     float64
+	}{
+		{"p95 < 300ms", true, 250},
+		{"p95 < 250ms", false, 250},
+		{"p95 <= 250ms", true, 250},
+		{"errorRate < 1%", false, 2},
+		{"achievedRps >= 0.95 * target", true, 96},
+		{"achievedRps > target", false, 96},
+		{"p99 == 0", true, 0}, // a missing metric counts as 0
+	}
+	for _, tt := range tests {
+		th, err := Parse(tt.expression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := th.Evaluate(values)
+		if r.Passed != tt.passed || r.Actual != tt.actual {
+			t.Errorf("%q: passed=%v actual=%v, want passed=%v actual=%v", tt.expression, r.Passed, r.Actual,
+				tt.passed, tt.actual)
+		}
+		if r.Expression != tt.expression {
+			t.Errorf("result expression %q, want %q", r.Expression, tt.expression)
+		}
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	if _, err := ParseAll([]string{"p95 < 300ms", "bogus"}); err == nil {
+		t.Error("ParseAll with an invalid expression did not fail")
+	}
+	thresholds, err := ParseAll([]string{"p95 < 300ms", "errorRate < 1%"})
+	if err != nil || len(thresholds) != 2 {
+		t.Errorf("ParseAll = %v %v, want 2 thresholds", thresholds, err)
+	}
+}
+
+func TestEvaluateAll(t *testing.T) {
+	results := EvaluateAll([]string{"p95 < 300ms", "bogus"}, map[string]float64{P95: 100})
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if !results[0].Passed {
+		t.Errorf("%s did not pass", results[0].Expression)
+	}
+	if results[1].Passed {
+		t.Error("an invalid expression passed")
+	}
+}
@@ -1,10 +1,6 @@
 package types
 
-import (
-	"fmt"
-	"log"
-	"strings"
-)
+import "strings"
 
 type KV struct {
 	Key   string `yaml:"key"`
@@ -25,27 +21,22 @@ type ApiConfig struct {
 	PathVariables []KV `yaml:"pathVariables"`
 	QueryParams   []KV `yaml:"queryParams"`
 	ReplaceParams []KV `yaml:"replaceParams"`
+	// Headers are sent with every request, their values can hold the keys of ReplaceParams
+	Headers    []KV             `yaml:"headers"`
+	Assertions Assertions       `yaml:"assertions"`
+	Client     HTTPClientConfig `yaml:"client"`
+	// Feeder provides the variables of the templates of every request, from the rows of a file
+	Feeder *FeederConfig `yaml:"feeder"`
 }
 
 type ApiScenarios map[string]ApiConfig
 
-func (a ApiConfig) ResolveEndPoint() string {
+// ResolvePath returns the url of the scenario with its path variables replaced, without its query params
+func (a ApiConfig) ResolvePath() string {
 	url := a.BaseUrl + a.Endpoint
-	if a.PathVariables != nil {
-		for _, v := range a.PathVariables {
-			placeholder := "{" + v.Key + "}"
-			url = strings.Replace(url, placeholder, v.Value, -1)
-		}
+	for _, v := range a.PathVariables {
+		placeholder := "{" + v.Key + "}"
+		url = strings.Replace(url, placeholder, v.Value, -1)
 	}
-	if a.QueryParams != nil {
-		url = url + "?"
-		var queryParams []string
-		for _, v := range a.QueryParams {
-			placeholder := fmt.Sprintf("%s=%s", v.Key, v.Value)
-			queryParams = append(queryParams, placeholder)
-		}
-		url = url + strings.Join(queryParams, "&")
-	}
-	log.Printf("finalUrl=%s", url)
 	return url
 }
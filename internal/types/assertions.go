@@ -0,0 +1,38 @@
+package types
+
+import (
+	"log"
+	"time"
+
+	"github.com/rk1165/loadsimulator/internal/assets"
+)
+
+// Assertions are checks on every response, a response fails when any of them fails
+type Assertions struct {
+	Status       []string      `yaml:"status"`       // accepted status codes such as 200, 200-204 or 2xx, expectedStatusCode when not set
+	Headers      []KV          `yaml:"headers"`      // required headers, whose value must contain Value when it is set
+	BodyContains []string      `yaml:"bodyContains"` // strings the body must contain
+	BodyRegex    []string      `yaml:"bodyRegex"`    // regular expressions the body must match
+	JSONPath     []PathEquals  `yaml:"jsonPath"`
+	XPath        []PathEquals  `yaml:"xPath"`
+	JSONSchema   string        `yaml:"jsonSchema"`  // file of the JSON schema the body must match
+	MaxBodySize  int64         `yaml:"maxBodySize"` // bytes
+	MaxLatency   time.Duration `yaml:"maxLatency"`
+}
+
+// PathEquals asserts the value at Path in the body is Equals
+type PathEquals struct {
+	Path   string `yaml:"path"`
+	Equals string `yaml:"equals"`
+}
+
+func (a Assertions) ResolveSchema() []byte {
+	if len(a.JSONSchema) == 0 {
+		return nil
+	}
+	bytes, err := assets.Data.ReadFile(a.JSONSchema)
+	if err != nil {
+		log.Fatalf("failed to read file: %s: %v", a.JSONSchema, err)
+	}
+	return bytes
+}
@@ -0,0 +1,33 @@
+package types
+
+import "time"
+
+// Values of HTTPClientConfig.HTTP2
+const (
+	HTTP2Auto    = ""        // HTTP/2 when the server negotiates it over TLS, HTTP/1.1 otherwise
+	HTTP2Force   = "force"   // HTTP/2 only, over TLS or in cleartext (h2c)
+	HTTP2Disable = "disable" // HTTP/1.1 only
+)
+
+// ProxyNone disables the proxy, including the one of the HTTP_PROXY and HTTPS_PROXY environment variables
+const ProxyNone = "none"
+
+// DefaultHTTPTimeout is the timeout of a request when none is configured
+const DefaultHTTPTimeout = 5 * time.Second
+
+// HTTPClientConfig is the client an HTTP scenario sends its requests with. The unset fields keep
+// the defaults of the Go transport
+type HTTPClientConfig struct {
+	Timeout             time.Duration `yaml:"timeout"`             // of a whole request including the body, DefaultHTTPTimeout when not set
+	DialTimeout         time.Duration `yaml:"dialTimeout"`         // of the TCP connection
+	TLSHandshakeTimeout time.Duration `yaml:"tlsHandshakeTimeout"` // of the TLS handshake
+	IdleConnTimeout     time.Duration `yaml:"idleConnTimeout"`     // after which an idle connection is closed
+	MaxIdleConns        int           `yaml:"maxIdleConns"`        // idle connections kept across all hosts
+	MaxIdleConnsPerHost int           `yaml:"maxIdleConnsPerHost"` // idle connections kept per host, 2 when not set
+	MaxConnsPerHost     int           `yaml:"maxConnsPerHost"`     // connections per host, unlimited when not set
+	// DisableKeepAlives opens a new connection for every request, like clients without a connection pool
+	DisableKeepAlives bool   `yaml:"disableKeepAlives"`
+	HTTP2             string `yaml:"http2"` // HTTP2Auto, HTTP2Force or HTTP2Disable
+	// Proxy is the url of the proxy, ProxyNone for none. When not set the environment variables are used
+	Proxy string `yaml:"proxy"`
+}
@@ -0,0 +1,16 @@
+package types
+
+// Strategies of a feeder, how the rows are picked for the requests
+const (
+	FeederCircular   = "circular"   // the rows in order, starting over after the last one
+	FeederRandom     = "random"     // a random row for every request
+	FeederSequential = "sequential" // the rows in order once, the run stops after the last one
+	FeederUnique     = "unique"     // the rows are split between the workers, the run stops once a worker used its share
+)
+
+// FeederConfig reads the rows of a CSV file, whose first line names the columns, or of a JSONL
+// file of objects. The columns of a row are the variables of the templates of a request
+type FeederConfig struct {
+	File     string `yaml:"file"`     // .csv, .jsonl or .ndjson
+	Strategy string `yaml:"strategy"` // FeederCircular by default
+}
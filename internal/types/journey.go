@@ -0,0 +1,54 @@
+package types
+
+import (
+	"log"
+
+	"github.com/rk1165/loadsimulator/internal/assets"
+)
+
+// JourneyConfig is a sequence of HTTP steps executed in order for every request of the load.
+// The base url, the credentials, the content type, the headers, the client and the feeder are
+// shared by the steps. The method, endpoint, params, body, expected status and assertions of
+// an http scenario are set per step and rejected on the journey
+type JourneyConfig struct {
+	ApiConfig `yaml:",inline"`
+	Steps     []JourneyStep `yaml:"steps"`
+}
+
+// JourneyStep is an HTTP call of a journey. The endpoint, the values of the query params and of
+// the headers and the body are templates, they can refer to the variables captured by the
+// previous steps as {{name}}
+type JourneyStep struct {
+	Name               string       `yaml:"name"`
+	Method             string       `yaml:"method"`
+	Endpoint           string       `yaml:"endpoint"`
+	QueryParams        []KV         `yaml:"queryParams"`
+	Headers            []KV         `yaml:"headers"`
+	Body               string       `yaml:"body"`
+	FileName           string       `yaml:"fileName"` // file of the body, when Body is not set
+	ExpectedStatusCode int          `yaml:"expectedStatusCode"`
+	Extract            []Extraction `yaml:"extract"` // values of the response captured into variables
+}
+
+// Extraction captures a value of a response into Variable, from exactly one of JSONPath, XPath,
+// Regex (the first group, or the whole match without a group) or Header
+type Extraction struct {
+	Variable string `yaml:"variable"`
+	JSONPath string `yaml:"jsonPath"`
+	XPath    string `yaml:"xPath"`
+	Regex    string `yaml:"regex"`
+	Header   string `yaml:"header"`
+}
+
+type JourneyScenarios map[string]JourneyConfig
+
+func (s JourneyStep) ResolveBody() string {
+	if s.Body != "" || len(s.FileName) == 0 {
+		return s.Body
+	}
+	bytes, err := assets.Data.ReadFile(s.FileName)
+	if err != nil {
+		log.Fatalf("failed to read file: %s: %v", s.FileName, err)
+	}
+	return string(bytes)
+}
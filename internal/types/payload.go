@@ -0,0 +1,17 @@
+package types
+
+// Payload is a variant of the payloads of a scenario. File is a file, a directory or a glob such
+// as data/orders/*.json, a request sending the variant sends one of its files picked at random
+type Payload struct {
+	Name   string `yaml:"name"` // name of the variant in the report, File when not set
+	File   string `yaml:"file"`
+	Weight int    `yaml:"weight"` // relative to the weights of the other variants, 1 when not set
+}
+
+// DisplayName is the name of the variant in the report
+func (p Payload) DisplayName() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.File
+}
@@ -0,0 +1,74 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	PlanModeConcurrent = "concurrent" // the scenarios run at the same time
+	PlanModeSuite      = "suite"      // setup steps, the scenarios one after the other, then teardown steps
+)
+
+// Phases of the scenarios of a suite, in the order they run
+const (
+	PhaseWarmup   = "warmup"   // excluded from the results the suite is judged on
+	PhaseMain     = "main"     // the load under test
+	PhaseCooldown = "cooldown" // how the target recovers after the load
+)
+
+// Actions of the steps of a suite
+const (
+	StepOnce      = "once"      // executes the load of the scenario a single time
+	StepS3Cleanup = "s3Cleanup" // deletes the objects uploaded by the s3 scenario
+)
+
+// Plan is a set of scenarios, possibly of different config types, run at the same time or as a suite
+type Plan struct {
+	Mode      string         `yaml:"mode"`  // concurrent (default) or suite
+	Setup     []PlanStep     `yaml:"setup"` // suite only: steps run before the scenarios, a failed one skips the scenarios
+	Scenarios []PlanScenario `yaml:"scenarios"`
+	Teardown  []PlanStep     `yaml:"teardown"` // suite only: steps run after the scenarios, even when they failed
+}
+
+// PlanScenario is a scenario of a plan, the scenario named Scenario of configs/<SubConfig>.yaml
+// or of Config
+type PlanScenario struct {
+	Name       string        `yaml:"name"` // name of the scenario in the logs and reports, Scenario when not set
+	ConfigType string        `yaml:"configType"`
+	SubConfig  string        `yaml:"subConfig"`
+	Scenario   string        `yaml:"scenario"`
+	Config     string        `yaml:"config"`     // path of the config file, configs/<SubConfig>.yaml when not set
+	StartAfter time.Duration `yaml:"startAfter"` // offset of the start of the scenario from the start of the plan, or of the previous scenario in a suite
+	Phase      string        `yaml:"phase"`      // suite only: warmup, main (default) or cooldown
+}
+
+// PlanStep is a one-off action of a suite on the target of a scenario
+type PlanStep struct {
+	PlanScenario `yaml:",inline"`
+	Action       string `yaml:"action"` // once (default) or s3Cleanup
+}
+
+// DisplayName is the name of the scenario in the logs and reports
+func (p PlanScenario) DisplayName() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.Scenario
+}
+
+// ConfigFile is the path of the config file holding the scenario
+func (p PlanScenario) ConfigFile() string {
+	if p.Config != "" {
+		return p.Config
+	}
+	return fmt.Sprintf("configs/%s.yaml", p.SubConfig)
+}
+
+// PhaseName is the phase of the scenario in a suite
+func (p PlanScenario) PhaseName() string {
+	if p.Phase != "" {
+		return p.Phase
+	}
+	return PhaseMain
+}
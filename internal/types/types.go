@@ -13,23 +13,88 @@ type Config struct {
 	Duration    int
 	Concurrency int
 	Jitter      time.Duration
-	InfoLog     *log.Logger
-	ErrorLog    *log.Logger
+	Arrival     string
+	Seed        int64
+	Stages      []Stage
+	Mode        string
+	Iterations  int
+	ThinkTime   ThinkTime
+	// HistogramPrecision is the number of significant digits kept by latency histograms
+	HistogramPrecision int
+	ReportInterval     time.Duration
+	TimeSeriesFile     string
+	Thresholds         []string
+	Abort              []AbortCondition
+	GracePeriod        time.Duration
+	ErrorPolicy        string
+	InfoLog            *log.Logger
+	ErrorLog           *log.Logger
 }
 
 type BaseConfig struct {
-	FileName      string `yaml:"fileName"`
-	RatePerSecond int    `yaml:"ratePerSec"`         // target operations per second
-	Duration      int    `yaml:"duration"`           // Total time for the operations to run
-	Concurrency   int    `yaml:"concurrentRequests"` // max in-flight tasks executing at the same time - implemented by starting that many worker goroutines generating the scheduled loads
-	//Jitter        time.Duration							// max random +/- scheduled fire (optional)
+	FileName      string           `yaml:"fileName"`
+	Payloads      []Payload        `yaml:"payloads"`           // weighted variants of the payload picked for every request, instead of fileName
+	RatePerSecond int              `yaml:"ratePerSec"`         // target operations per second
+	Duration      int              `yaml:"duration"`           // Total time for the operations to run
+	Concurrency   int              `yaml:"concurrentRequests"` // max in-flight tasks executing at the same time - implemented by starting that many worker goroutines generating the scheduled loads
+	Jitter        time.Duration    `yaml:"jitter"`             // max random +/- scheduled fire (optional), used by the uniform arrival model
+	Arrival       string           `yaml:"arrival"`            // inter-arrival model: constant (default), uniform or poisson
+	Seed          int64            `yaml:"seed"`               // seed of the random arrival models, a random one is picked when 0
+	Stages        []Stage          `yaml:"stages"`             // load profile, when present ratePerSec and duration are ignored
+	Mode          string           `yaml:"mode"`               // open (default) fires at a rate, closed loops concurrentRequests virtual users
+	Iterations    int              `yaml:"iterations"`         // closed mode only: iterations of every virtual user, unlimited when 0
+	ThinkTime     ThinkTime        `yaml:"thinkTime"`          // closed mode only: pause of a virtual user between two iterations
+	Precision     int              `yaml:"histogramPrecision"` // significant digits (1 to 5) of the recorded latencies, 3 when not set
+	Report        ReportConfig     `yaml:"report"`
+	Thresholds    []string         `yaml:"thresholds"`  // conditions the run must meet to pass, e.g. "p95 < 300ms"
+	Abort         []AbortCondition `yaml:"abortOn"`     // conditions stopping the run early
+	GracePeriod   time.Duration    `yaml:"gracePeriod"` // time given to the requests in flight when the run is interrupted, 10s when not set
+	ErrorPolicy   string           `yaml:"errorPolicy"` // fail (default) fails the run when any request returned an error, ignore only counts them
+}
+
+// AbortCondition stops the run as soon as Condition, written like a threshold, holds over the last Window
+type AbortCondition struct {
+	Condition string        `yaml:"condition"`
+	Window    time.Duration `yaml:"window"` // length of the window the condition is evaluated on, the report interval when not set
+}
+
+// ReportConfig configures the metrics reported while the load is running
+type ReportConfig struct {
+	Interval       time.Duration `yaml:"interval"`       // length of a reporting window, 1s when not set
+	TimeSeriesFile string        `yaml:"timeSeriesFile"` // csv file of the windows, logs/<scenario>_timeseries.csv when not set
+}
+
+// ThinkTime is a fixed pause when Max is not set, else a random one between Min and Max
+type ThinkTime struct {
+	Min time.Duration `yaml:"min"`
+	Max time.Duration `yaml:"max"`
+}
+
+// Stage is one segment of a load profile. The rate moves linearly from the target of the
+// previous stage (0 for the first one) to Target over Duration seconds. A stage with a
+// Duration of 0 jumps straight to its Target
+type Stage struct {
+	Duration int `yaml:"duration"` // seconds spent in the stage
+	Target   int `yaml:"target"`   // operations per second reached at the end of the stage
 }
 
 type Provider interface {
 	GetRatePerSecond() int
 	GetConcurrentRequests() int
 	GetDuration() int
-	//GetJitter() time.Duration
+	GetStages() []Stage
+	GetJitter() time.Duration
+	GetArrival() string
+	GetSeed() int64
+	GetMode() string
+	GetIterations() int
+	GetThinkTime() ThinkTime
+	GetHistogramPrecision() int
+	GetReport() ReportConfig
+	GetThresholds() []string
+	GetAbort() []AbortCondition
+	GetGracePeriod() time.Duration
+	GetErrorPolicy() string
 }
 
 func (b BaseConfig) GetRatePerSecond() int {
@@ -44,11 +109,63 @@ func (b BaseConfig) GetConcurrentRequests() int {
 	return b.Concurrency
 }
 
+func (b BaseConfig) GetStages() []Stage {
+	return b.Stages
+}
+
+func (b BaseConfig) GetJitter() time.Duration {
+	return b.Jitter
+}
+
+func (b BaseConfig) GetArrival() string {
+	return b.Arrival
+}
+
+func (b BaseConfig) GetSeed() int64 {
+	return b.Seed
+}
+
+func (b BaseConfig) GetMode() string {
+	return b.Mode
+}
+
+func (b BaseConfig) GetIterations() int {
+	return b.Iterations
+}
+
+func (b BaseConfig) GetThinkTime() ThinkTime {
+	return b.ThinkTime
+}
+
+func (b BaseConfig) GetHistogramPrecision() int {
+	return b.Precision
+}
+
+func (b BaseConfig) GetReport() ReportConfig {
+	return b.Report
+}
+
+func (b BaseConfig) GetThresholds() []string {
+	return b.Thresholds
+}
+
+func (b BaseConfig) GetAbort() []AbortCondition {
+	return b.Abort
+}
+
+func (b BaseConfig) GetGracePeriod() time.Duration {
+	return b.GracePeriod
+}
+
+func (b BaseConfig) GetErrorPolicy() string {
+	return b.ErrorPolicy
+}
+
 func (b BaseConfig) ResolveBody() string {
 	if len(b.FileName) == 0 {
 		return ""
 	}
-	bytes, err := assets.FS.ReadFile(b.FileName)
+	bytes, err := assets.Data.ReadFile(b.FileName)
 	if err != nil {
 		log.Fatalf("failed to read file: %s: %v", b.FileName, err)
 	}